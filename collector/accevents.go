@@ -0,0 +1,211 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	accountEventsEndpoint = "account_events"
+	accountConnsSubject   = "$SYS.ACCOUNT.*.SERVER.CONNS"
+)
+
+// accountEventsMaxAge is how long the statistics of an account are reported
+// after the timestamp of its last event.  The servers send the events of
+// the accounts with connections every 30 seconds, and a last one once an
+// account has none left.
+var accountEventsMaxAge = 90 * time.Second
+
+// EventCollector is a collector updated from events, which is started once
+// registered and stopped once unregistered.
+type EventCollector interface {
+	prometheus.Collector
+	Start() error
+	Stop()
+}
+
+func isAccountEventsEndpoint(system, endpoint string) bool {
+	return system == CoreSystem && endpoint == accountEventsEndpoint
+}
+
+// accountConnsEvent is the account statistics event periodically sent by
+// the servers for each of their accounts.
+type accountConnsEvent struct {
+	Time   time.Time `json:"timestamp"`
+	Server struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+	} `json:"server"`
+	Account       string           `json:"acc"`
+	Conns         float64          `json:"conns"`
+	LeafNodes     float64          `json:"leafnodes"`
+	TotalConns    float64          `json:"total_conns"`
+	Sent          accountDataStats `json:"sent"`
+	Received      accountDataStats `json:"received"`
+	SlowConsumers float64          `json:"slow_consumers"`
+}
+
+type accountDataStats struct {
+	Msgs  float64 `json:"msgs"`
+	Bytes float64 `json:"bytes"`
+}
+
+// accountEventsCollector reports the last account statistics received
+// from the system account events of the servers, instead of polling them,
+// until they are older than accountEventsMaxAge.
+// The series are labeled with the ID of the server sending the events, as
+// any server of a cluster can be reached from a single connection.
+type accountEventsCollector struct {
+	sync.Mutex
+	*metricDescs
+
+	servers []*CollectedServer
+	subs    []*nats.Subscription
+	stats   map[string]*accountConnsEvent
+
+	conns         *prometheus.Desc
+	leafNodes     *prometheus.Desc
+	totalConns    *prometheus.Desc
+	sentMsgs      *prometheus.Desc
+	sentBytes     *prometheus.Desc
+	receivedMsgs  *prometheus.Desc
+	receivedBytes *prometheus.Desc
+	slowConsumers *prometheus.Desc
+}
+
+func newAccountEventsCollector(system string, servers []*CollectedServer) prometheus.Collector {
+	labels := []string{"server_id", "server_name", "account"}
+	descs := &metricDescs{}
+	gauge := func(name, help string) *prometheus.Desc {
+		return descs.gauge(prometheus.BuildFQName(system, "account", name), help, labels)
+	}
+	counter := func(name, help string) *prometheus.Desc {
+		return descs.counter(prometheus.BuildFQName(system, "account", name), help, labels)
+	}
+	return &accountEventsCollector{
+		metricDescs:   descs,
+		servers:       servers,
+		stats:         make(map[string]*accountConnsEvent),
+		conns:         gauge("connections", "Number of client connections to the account"),
+		leafNodes:     gauge("leafnodes", "Number of leaf node connections to the account"),
+		totalConns:    gauge("total_connections", "Number of client and leaf node connections to the account"),
+		sentMsgs:      counter("sent_msgs", "Number of messages sent by the account"),
+		sentBytes:     counter("sent_bytes", "Number of bytes sent by the account"),
+		receivedMsgs:  counter("received_msgs", "Number of messages received by the account"),
+		receivedBytes: counter("received_bytes", "Number of bytes received by the account"),
+		slowConsumers: counter("slow_consumers", "Number of slow consumers of the account"),
+	}
+}
+
+// Start subscribes to the account events through the system account
+// connection of the servers, once for the servers sharing a connection,
+// through which the events of all of them are received.
+func (nc *accountEventsCollector) Start() error {
+	nc.Lock()
+	defer nc.Unlock()
+
+	subscribed := make(map[*nats.Conn]bool)
+	for _, server := range nc.servers {
+		if !isSystemURL(server.URL) {
+			Noticef("Skipping account events of server %s, not polled through the system account", server.ID)
+			continue
+		}
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			return err
+		}
+		conn, err := systemConn(u)
+		if err != nil {
+			return err
+		}
+		if subscribed[conn] {
+			continue
+		}
+		subscribed[conn] = true
+		sub, err := conn.Subscribe(accountConnsSubject, nc.handleEvent)
+		if err != nil {
+			return fmt.Errorf("unable to subscribe to the account events of %s: %v", server.ID, err)
+		}
+		nc.subs = append(nc.subs, sub)
+	}
+	return nil
+}
+
+// Stop unsubscribes from the account events.
+func (nc *accountEventsCollector) Stop() {
+	nc.Lock()
+	defer nc.Unlock()
+
+	for _, sub := range nc.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			Debugf("Unable to unsubscribe from the account events: %v", err)
+		}
+	}
+	nc.subs = nil
+}
+
+func (nc *accountEventsCollector) handleEvent(msg *nats.Msg) {
+	var ev accountConnsEvent
+	if err := json.Unmarshal(msg.Data, &ev); err != nil {
+		Debugf("Ignoring invalid account event on %s: %v", msg.Subject, err)
+		return
+	}
+	if ev.Account == "" || ev.Server.ID == "" {
+		return
+	}
+	// Events without a timestamp are dated when received.
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	nc.Lock()
+	defer nc.Unlock()
+	key := ev.Server.ID + " " + ev.Account
+	if last, ok := nc.stats[key]; ok && ev.Time.Before(last.Time) {
+		return
+	}
+	nc.stats[key] = &ev
+}
+
+// Collect reports the last statistics received for each account, and
+// drops those older than accountEventsMaxAge, e.g. of the accounts
+// removed or of the servers gone.
+func (nc *accountEventsCollector) Collect(ch chan<- prometheus.Metric) {
+	nc.Lock()
+	defer nc.Unlock()
+
+	for key, ev := range nc.stats {
+		if time.Since(ev.Time) > accountEventsMaxAge {
+			delete(nc.stats, key)
+			continue
+		}
+		labels := []string{ev.Server.ID, ev.Server.Name, ev.Account}
+		ch <- prometheus.MustNewConstMetric(nc.conns, prometheus.GaugeValue, ev.Conns, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.leafNodes, prometheus.GaugeValue, ev.LeafNodes, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.totalConns, prometheus.GaugeValue, ev.TotalConns, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.sentMsgs, prometheus.CounterValue, ev.Sent.Msgs, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.sentBytes, prometheus.CounterValue, ev.Sent.Bytes, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.receivedMsgs, prometheus.CounterValue, ev.Received.Msgs, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.receivedBytes, prometheus.CounterValue, ev.Received.Bytes, labels...)
+		ch <- prometheus.MustNewConstMetric(nc.slowConsumers, prometheus.CounterValue, ev.SlowConsumers, labels...)
+	}
+}
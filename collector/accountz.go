@@ -0,0 +1,140 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// isAccountzEndpoint returns wether an endpoint is an accountz or not.
+func isAccountzEndpoint(system, endpoint string) bool {
+	return system == CoreSystem && endpoint == "accountz"
+}
+
+// accountzCollector gathers the number of accounts of each server.
+type accountzCollector struct {
+	*metricDescs
+	httpClient *http.Client
+	servers    []*CollectedServer
+	limits     bool
+
+	accounts         *prometheus.Desc
+	subscriptions    *prometheus.Desc
+	maxSubscriptions *prometheus.Desc
+}
+
+// Accountz is the list of the accounts of a server.
+type Accountz struct {
+	ID       string   `json:"server_id"`
+	Accounts []string `json:"accounts"`
+}
+
+// AccountzDetail is the detail of an account of a server, limited to its
+// subscriptions.
+type AccountzDetail struct {
+	Account *struct {
+		Name          string  `json:"account_name"`
+		Subscriptions float64 `json:"subscriptions"`
+		Claim         *struct {
+			Nats struct {
+				Limits struct {
+					Subs *float64 `json:"subs"`
+				} `json:"limits"`
+			} `json:"nats"`
+		} `json:"decoded_jwt"`
+	} `json:"account_detail"`
+}
+
+func newAccountzCollector(system, endpoint string, servers []*CollectedServer,
+	opts *CollectorOptions) prometheus.Collector {
+	accountLabels := []string{"server_id", "account"}
+	descs := &metricDescs{}
+	nc := &accountzCollector{
+		metricDescs: descs,
+		httpClient: &http.Client{
+			Transport: opts.Transport,
+			Timeout:   5 * time.Second,
+		},
+		limits: opts.AccountzLimits,
+		accounts: descs.gauge(
+			prometheus.BuildFQName(system, endpoint, "accounts"),
+			"Number of accounts on the server",
+			[]string{"server_id"},
+		),
+		subscriptions: descs.gauge(
+			prometheus.BuildFQName(system, endpoint, "account_subscriptions"),
+			"Number of subscriptions of the account",
+			accountLabels,
+		),
+	}
+	if nc.limits {
+		nc.maxSubscriptions = descs.gauge(
+			prometheus.BuildFQName(system, endpoint, "account_max_subscriptions"),
+			"Maximum number of subscriptions of the account",
+			accountLabels,
+		)
+	}
+	nc.servers = make([]*CollectedServer, len(servers))
+	for i, s := range servers {
+		nc.servers[i] = &CollectedServer{
+			ID:  s.ID,
+			URL: s.URL + "/accountz",
+		}
+	}
+	return nc
+}
+
+// Collect gathers the server accountz metrics.
+func (nc *accountzCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, server := range nc.servers {
+		var resp Accountz
+		if err := getMetricURL(nc.httpClient, server.URL, &resp); err != nil {
+			Debugf("ignoring server %s: %v", server.ID, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(nc.accounts, prometheus.GaugeValue,
+			float64(len(resp.Accounts)), server.ID)
+
+		if nc.limits {
+			for _, account := range resp.Accounts {
+				nc.collectAccountLimits(server, account, ch)
+			}
+		}
+	}
+}
+
+// collectAccountLimits reports the subscriptions of an account along with
+// their limit, which is only reported for the accounts with a JWT limiting
+// them.
+func (nc *accountzCollector) collectAccountLimits(server *CollectedServer, account string,
+	ch chan<- prometheus.Metric) {
+	var resp AccountzDetail
+	if err := getMetricURL(nc.httpClient, server.URL+"?acc="+url.QueryEscape(account), &resp); err != nil {
+		Debugf("ignoring account %s of server %s: %v", account, server.ID, err)
+		return
+	}
+	if resp.Account == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(nc.subscriptions, prometheus.GaugeValue,
+		resp.Account.Subscriptions, server.ID, account)
+	if c := resp.Account.Claim; c != nil && c.Nats.Limits.Subs != nil && *c.Nats.Limits.Subs >= 0 {
+		ch <- prometheus.MustNewConstMetric(nc.maxSubscriptions, prometheus.GaugeValue,
+			*c.Nats.Limits.Subs, server.ID, account)
+	}
+}
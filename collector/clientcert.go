@@ -0,0 +1,131 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// clientCertificate is a client certificate loaded from its files, and
+// loaded again when they change, e.g. rotated by cert-manager.
+type clientCertificate struct {
+	mu       sync.Mutex
+	certFile string
+	keyFile  string
+	certMod  fileVersion
+	keyMod   fileVersion
+	cert     *tls.Certificate
+}
+
+// fileVersion identifies the content of a file by its modification time
+// and size.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFileVersion(name string) (fileVersion, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileVersion{}, err
+	}
+	return fileVersion{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
+
+func newClientCertificate(certFile, keyFile string) (*clientCertificate, error) {
+	c := &clientCertificate{certFile: certFile, keyFile: keyFile}
+	if _, err := c.get(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// get returns the certificate, loaded again when either file changed since
+// it was loaded.  The previous certificate is kept while the files cannot
+// be loaded, e.g. the certificate is written but not the key yet.
+func (c *clientCertificate) get() (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	certMod, err := statFileVersion(c.certFile)
+	if err != nil {
+		return c.keepCertificate(err)
+	}
+	keyMod, err := statFileVersion(c.keyFile)
+	if err != nil {
+		return c.keepCertificate(err)
+	}
+	if c.cert != nil && certMod == c.certMod && keyMod == c.keyMod {
+		return c.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return c.keepCertificate(err)
+	}
+	if c.cert != nil {
+		Noticef("loaded the client certificate %s again", c.certFile)
+	}
+	c.cert, c.certMod, c.keyMod = &cert, certMod, keyMod
+	return c.cert, nil
+}
+
+// keepCertificate returns the certificate loaded before, if any, when the
+// files cannot be loaded.
+// Caller must lock.
+func (c *clientCertificate) keepCertificate(err error) (*tls.Certificate, error) {
+	if c.cert == nil {
+		return nil, fmt.Errorf("unable to load the client certificate %s: %v", c.certFile, err)
+	}
+	Errorf("keeping the previous client certificate %s: %v", c.certFile, err)
+	return c.cert, nil
+}
+
+// getClientCertificate implements tls.Config.GetClientCertificate.
+func (c *clientCertificate) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.get()
+}
+
+// withClientCertificate returns the TLS configuration of a transport
+// presenting the client certificate, if any.
+func withClientCertificate(config *tls.Config, c *clientCertificate) *tls.Config {
+	if c == nil {
+		return config
+	}
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config.GetClientCertificate = c.getClientCertificate
+	return config
+}
+
+// SetClientCertificate makes the transport present the client certificate
+// of the files to the servers over HTTPS, loaded again when the files
+// change.  It must be set before the transport is used.
+func (t *Transport) SetClientCertificate(certFile, keyFile string) error {
+	c, err := newClientCertificate(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base.TLSClientConfig = withClientCertificate(t.base.TLSClientConfig, c)
+	for _, p := range t.pinned {
+		p.TLSClientConfig = withClientCertificate(p.TLSClientConfig, c)
+	}
+	return nil
+}
@@ -16,8 +16,10 @@ package collector
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -40,11 +42,64 @@ var (
 type CollectedServer struct {
 	URL string
 	ID  string
+
+	// TLSPinnedSHA256 is the SHA-256 fingerprint of the certificate of the
+	// server, trusted over HTTPS instead of verifying it against the CAs.
+	TLSPinnedSHA256 string
+}
+
+// CollectorOptions are optional settings tuning what the collectors poll
+// and report.
+type CollectorOptions struct {
+	// ConnzIdleTopN, when positive, makes the connz collector report the
+	// idle time of the N connections which have been idle the longest.
+	ConnzIdleTopN int
+
+	// ConnzStreamThreshold, when positive, makes the connz collector decode
+	// the connections of the responses larger than this many bytes one at a
+	// time, instead of all at once.
+	ConnzStreamThreshold int64
+
+	// EndpointConcurrency, when positive, limits the number of requests to
+	// the monitoring endpoints of each server running at once, through the
+	// transport created when Transport is not set.
+	EndpointConcurrency int
+
+	// Transport, when set, is the transport of the requests to the servers,
+	// shared by the collectors using it, whose state lasts until it is
+	// closed.  Each collector has its own transport otherwise.
+	Transport *Transport
+
+	// AccountzLimits makes the accountz collector report the subscriptions
+	// of each account along with their limit, when reported.
+	AccountzLimits bool
+
+	// ConnzAccountKinds makes the connz collector report the number of
+	// connections of each account by kind: client, leaf or route.
+	ConnzAccountKinds bool
+
+	// ConnzSlowConsumersByAccount makes the connz collector report the
+	// number of slow consumers of each account, from the closed connections.
+	ConnzSlowConsumersByAccount bool
+
+	// ConnzTLSVersions makes the connz collector report the number of
+	// connections using each TLS version, going through all the pages of
+	// connections.
+	ConnzTLSVersions bool
+
+	// JszDomain, when set, makes the jsz collector only report the servers
+	// in this JetStream domain.
+	JszDomain string
+
+	// JszStreamSubjects makes the jsz collector report the subjects captured
+	// by each stream, one series per subject.
+	JszStreamSubjects bool
 }
 
 type metric struct {
 	path   []string
 	metric interface{}
+	desc   *MetricDesc
 }
 
 // NATSCollector collects NATS metrics
@@ -103,11 +158,27 @@ func newLabelGauge(system, subsystem, name, help, prefix, label string) *prometh
 	return metric
 }
 
+// gaugeVecDesc returns the description of the metrics of a gauge vector.
+func gaugeVecDesc(vec *prometheus.GaugeVec, fqName, help string, labels []string) *MetricDesc {
+	ch := make(chan *prometheus.Desc, 1)
+	vec.Describe(ch)
+	return &MetricDesc{Desc: <-ch, Name: fqName, Help: help, Type: prometheus.GaugeValue, Labels: labels}
+}
+
 // GetMetricURL retrieves a NATS Metrics JSON.
 // This can be called against any monitoring URL for NATS.
 // On any this function will error, warn and return nil.
-func getMetricURL(httpClient *http.Client, url string, response interface{}) error {
-	resp, err := httpClient.Get(url)
+func getMetricURL(httpClient *http.Client, url string, response interface{}) (err error) {
+	defer func() { recordFetchResult(url, err) }()
+	if isSystemURL(url) {
+		start := time.Now()
+		err := getSystemMetric(url, response)
+		if t, ok := transportOf(httpClient); ok && err == nil {
+			t.recordFetchRTT(url, time.Since(start))
+		}
+		return err
+	}
+	resp, err := httpGet(httpClient, url)
 	if err != nil {
 		return err
 	}
@@ -117,26 +188,89 @@ func getMetricURL(httpClient *http.Client, url string, response interface{}) err
 		return err
 	}
 	Tracef("Retrieved metric result:\n%s\n", string(body))
+	recordResponse(url, body)
 	return json.Unmarshal(body, &response)
 }
 
 // GetServerIDFromVarz gets the server ID from the server.
 func GetServerIDFromVarz(endpoint string, retryInterval time.Duration) string {
-	return getServerKeyFromVarz(endpoint, retryInterval, "server_id")
+	t := NewTransport(0)
+	defer t.Close()
+	return t.GetServerIDFromVarz(endpoint, retryInterval)
 }
 
 // GetServerNameFromVarz gets the server name from the server.
 func GetServerNameFromVarz(endpoint string, retryInterval time.Duration) string {
-	return getServerKeyFromVarz(endpoint, retryInterval, "server_name")
+	t := NewTransport(0)
+	defer t.Close()
+	return t.GetServerNameFromVarz(endpoint, retryInterval)
+}
+
+// QueryServerIDFromVarz gets the server ID from the server, without
+// retrying if the server is not available.
+func QueryServerIDFromVarz(endpoint string) (string, error) {
+	t := NewTransport(0)
+	defer t.Close()
+	return t.QueryServerIDFromVarz(endpoint)
+}
+
+// QueryClusterNameFromVarz gets the name of the cluster of the server,
+// empty when it is not clustered, without retrying if the server is not
+// available.
+func QueryClusterNameFromVarz(endpoint string) (string, error) {
+	t := NewTransport(0)
+	defer t.Close()
+	return t.QueryClusterNameFromVarz(endpoint)
+}
+
+// GetServerIDFromVarz gets the server ID from the server through the
+// transport.
+func (t *Transport) GetServerIDFromVarz(endpoint string, retryInterval time.Duration) string {
+	return getServerKeyFromVarz(&http.Client{Transport: t}, endpoint, retryInterval, "server_id")
+}
+
+// GetServerNameFromVarz gets the server name from the server through the
+// transport.
+func (t *Transport) GetServerNameFromVarz(endpoint string, retryInterval time.Duration) string {
+	return getServerKeyFromVarz(&http.Client{Transport: t}, endpoint, retryInterval, "server_name")
+}
+
+// QueryServerIDFromVarz gets the server ID from the server through the
+// transport, without retrying if the server is not available.
+func (t *Transport) QueryServerIDFromVarz(endpoint string) (string, error) {
+	var varz struct {
+		ServerID string `json:"server_id"`
+	}
+	if err := getMetricURL(&http.Client{Transport: t}, endpoint+"/varz", &varz); err != nil {
+		return "", err
+	}
+	if varz.ServerID == "" {
+		return "", fmt.Errorf("could not find server_id in /varz")
+	}
+	return varz.ServerID, nil
+}
+
+// QueryClusterNameFromVarz gets the name of the cluster of the server
+// through the transport, without retrying if the server is not available.
+func (t *Transport) QueryClusterNameFromVarz(endpoint string) (string, error) {
+	var varz struct {
+		Cluster struct {
+			Name string `json:"name"`
+		} `json:"cluster"`
+	}
+	if err := getMetricURL(&http.Client{Transport: t}, endpoint+"/varz", &varz); err != nil {
+		return "", err
+	}
+	return varz.Cluster.Name, nil
 }
 
-func getServerKeyFromVarz(endpoint string, retryInterval time.Duration, key string) string {
+func getServerKeyFromVarz(httpClient *http.Client, endpoint string, retryInterval time.Duration, key string) string {
 	// Retry periodically until available, in case it never starts
 	// then a liveness check against the NATS Server itself should
 	// detect that an restart the server, in terms of the exporter
 	// we just wait for it to eventually be available.
 	getServerVarzValue := func() (string, error) {
-		resp, err := http.DefaultClient.Get(endpoint + "/varz")
+		resp, err := httpClient.Get(endpoint + "/varz")
 		if err != nil {
 			return "", err
 		}
@@ -200,6 +334,21 @@ func (nc *NATSCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 }
 
+// MetricDescs implements MetricDescriber.
+func (nc *NATSCollector) MetricDescs() []*MetricDesc {
+	nc.Lock()
+	defer nc.Unlock()
+
+	descs := make([]*MetricDesc, 0, len(nc.Stats))
+	for _, stat := range nc.Stats {
+		descs = append(descs, stat.desc)
+	}
+	sort.Slice(descs, func(i, j int) bool {
+		return descs[i].Name < descs[j].Name
+	})
+	return descs
+}
+
 // makeRequests makes HTTP request to the NATS server(s) monitor URLs and returns
 // a map of responses.
 func (nc *NATSCollector) makeRequests() map[string]map[string]interface{} {
@@ -275,33 +424,18 @@ func (nc *NATSCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
-// initMetricsFromServers builds the configuration
-// For each NATS Metrics endpoint (/*z) get the first URL
-// to determine the list of possible metrics.
-func (nc *NATSCollector) initMetricsFromServers(namespace string) {
-	var response map[string]interface{}
-
+// initMetricsFromSchema builds the configuration
+// For each NATS Metrics endpoint (/*z) use the fields of its response
+// to determine the list of possible metrics, whether or not the servers
+// are available.
+func (nc *NATSCollector) initMetricsFromSchema(namespace string) {
 	nc.Stats = make(map[string]metric)
 
-	// gets URLs until one responds.
-	for _, v := range nc.servers {
-		Tracef("Initializing metrics collection from: %s", v.URL)
-		if err := getMetricURL(nc.httpClient, v.URL, &response); err != nil {
-			// if a server is not running, silently ignore it.
-
-			isConnectErr := strings.Contains(err.Error(), "connection refused") ||
-				strings.Contains(err.Error(), "cannot assign requested address")
-			if isConnectErr {
-				Debugf("Unable to connect to the NATS server: %v", err)
-			} else {
-				// TODO:  Do not retry for other errors?
-				Errorf("Error loading metric config from response: %s", err)
-			}
-		} else {
-			break
-		}
+	response, ok := schemaResponse(nc.endpoint)
+	if !ok {
+		Debugf("No metrics known for endpoint %s", nc.endpoint)
+		return
 	}
-
 	nc.objectToMetrics(response, namespace)
 }
 
@@ -352,17 +486,23 @@ func (nc *NATSCollector) objectToMetrics(response map[string]interface{}, namesp
 		i := response[k]
 		switch v := i.(type) {
 		case float64: // all json numbers are handled here.
+			vec := newPrometheusGaugeVec(nc.system, nc.endpoint, fqn, "", namespace)
 			nc.Stats[fqn] = metric{
 				path:   path,
-				metric: newPrometheusGaugeVec(nc.system, nc.endpoint, fqn, "", namespace),
+				metric: vec,
+				desc: gaugeVecDesc(vec, prometheus.BuildFQName(namespace, nc.endpoint, fqn), fqn,
+					[]string{"server_id"}),
 			}
 		case string:
 			if _, ok := labelKeys[k]; !ok {
 				break
 			}
+			vec := newLabelGauge(nc.system, nc.endpoint, fqn, "", namespace, "value")
 			nc.Stats[fqn] = metric{
 				path:   path,
-				metric: newLabelGauge(nc.system, nc.endpoint, fqn, "", namespace, "value"),
+				metric: vec,
+				desc: gaugeVecDesc(vec, prometheus.BuildFQName(namespace, nc.endpoint, fqn), fqn,
+					[]string{"server_id", "value"}),
 			}
 		case map[string]interface{}:
 			// recurse and flatten
@@ -374,12 +514,9 @@ func (nc *NATSCollector) objectToMetrics(response map[string]interface{}, namesp
 	}
 }
 
-func newNatsCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
-	// TODO:  Potentially add TLS config in the transport.
-	tr := &http.Transport{}
-	hc := &http.Client{Transport: tr}
+func newNatsCollector(system, endpoint string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
 	nc := &NATSCollector{
-		httpClient: hc,
+		httpClient: &http.Client{Transport: transport},
 		system:     system,
 		endpoint:   endpoint,
 	}
@@ -394,7 +531,7 @@ func newNatsCollector(system, endpoint string, servers []*CollectedServer) prome
 		}
 	}
 
-	nc.initMetricsFromServers(system)
+	nc.initMetricsFromSchema(system)
 
 	return nc
 }
@@ -416,26 +553,63 @@ func boolToFloat(b bool) float64 {
 // NewCollector creates a new NATS Collector from a list of monitoring URLs.
 // Each URL should be to a specific endpoint (e.g. varz, connz, healthz, subsz, or routez)
 func NewCollector(system, endpoint, prefix string, servers []*CollectedServer) prometheus.Collector {
+	return NewCollectorWithOptions(system, endpoint, prefix, servers, nil)
+}
+
+// NewCollectorWithOptions creates a new NATS Collector like NewCollector,
+// applying the optional collector settings.
+func NewCollectorWithOptions(system, endpoint, prefix string, servers []*CollectedServer,
+	opts *CollectorOptions) prometheus.Collector {
+	if opts == nil {
+		opts = &CollectorOptions{}
+	}
+	if opts.Transport == nil {
+		o := *opts
+		o.Transport = NewTransport(opts.EndpointConcurrency)
+		opts = &o
+	}
+	opts.Transport.PinServerCertificates(servers)
+	if isFetchRTTEndpoint(system, endpoint) {
+		return newFetchRTTCollector(servers, opts.Transport)
+	}
+	if isConfiguredServersEndpoint(system, endpoint) {
+		return newConfiguredServersCollector(servers)
+	}
+	if isScrapeStreakEndpoint(system, endpoint) {
+		return newScrapeStreakCollector(servers)
+	}
+	if isHeapEndpoint(system, endpoint) {
+		return newHeapCollector()
+	}
 	if isStreamingEndpoint(system, endpoint) {
-		return newStreamingCollector(getSystem(system, prefix), endpoint, servers)
+		return newStreamingCollector(getSystem(system, prefix), endpoint, servers, opts.Transport)
 	}
 	if isHealthzEndpoint(system, endpoint) {
-		return newHealthzCollector(getSystem(system, prefix), endpoint, servers)
+		return newHealthzCollector(getSystem(system, prefix), endpoint, servers, opts.Transport)
 	}
 	if isConnzEndpoint(system, endpoint) {
-		return newConnzCollector(getSystem(system, prefix), endpoint, servers)
+		return newConnzCollector(getSystem(system, prefix), endpoint, servers, opts)
 	}
 	if isGatewayzEndpoint(system, endpoint) {
-		return newGatewayzCollector(getSystem(system, prefix), endpoint, servers)
+		return newGatewayzCollector(getSystem(system, prefix), endpoint, servers, opts.Transport)
 	}
 	if isLeafzEndpoint(system, endpoint) {
-		return newLeafzCollector(getSystem(system, prefix), endpoint, servers)
+		return newLeafzCollector(getSystem(system, prefix), endpoint, servers, opts.Transport)
 	}
 	if isReplicatorEndpoint(system, endpoint) {
-		return newReplicatorCollector(getSystem(system, prefix), servers)
+		return newReplicatorCollector(getSystem(system, prefix), servers, opts.Transport)
+	}
+	if isAccountzEndpoint(system, endpoint) {
+		return newAccountzCollector(getSystem(system, prefix), endpoint, servers, opts)
+	}
+	if isAccountEventsEndpoint(system, endpoint) {
+		return newAccountEventsCollector(getSystem(system, prefix), servers)
+	}
+	if isRoutezEndpoint(system, endpoint) {
+		return newRoutezCollector(getSystem(system, prefix), endpoint, servers, opts.Transport)
 	}
 	if isJszEndpoint(system) {
-		return newJszCollector(getSystem(system, prefix), endpoint, servers)
+		return newJszCollector(getSystem(system, prefix), endpoint, servers, opts)
 	}
-	return newNatsCollector(getSystem(system, prefix), endpoint, servers)
+	return newNatsCollector(getSystem(system, prefix), endpoint, servers, opts.Transport)
 }
@@ -15,9 +15,12 @@
 package collector
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -37,10 +40,17 @@ func isConnzEndpoint(system, endpoint string) bool {
 
 type connzCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient *http.Client
 	servers    []*CollectedServer
 	detailed   bool
+	idleTopN   int
+
+	streamThreshold      int64
+	slowConsumersAccount bool
+	accountKinds         bool
+	tlsVersionCounts     bool
 
 	numConnections     *prometheus.Desc
 	total              *prometheus.Desc
@@ -52,6 +62,10 @@ type connzCollector struct {
 	totalOutBytes      *prometheus.Desc
 	totalInMsgs        *prometheus.Desc
 	totalOutMsgs       *prometheus.Desc
+	connIdle           *prometheus.Desc
+	tlsVersions        *prometheus.Desc
+	slowConsumers      *prometheus.Desc
+	accountConnections *prometheus.Desc
 	connzCollectorDetailed
 }
 
@@ -71,67 +85,58 @@ type connzCollectorDetailed struct {
 
 func createConnzCollector(system string) *connzCollector {
 	summaryLabels := []string{"server_id"}
+	descs := &metricDescs{}
 	return &connzCollector{
-		httpClient: http.DefaultClient,
-		numConnections: prometheus.NewDesc(
+		metricDescs: descs,
+		numConnections: descs.gauge(
 			prometheus.BuildFQName(system, connzEndpoint, "num_connections"),
 			"num_connections",
 			summaryLabels,
-			nil,
 		),
-		offset: prometheus.NewDesc(
+		offset: descs.gauge(
 			prometheus.BuildFQName(system, connzEndpoint, "offset"),
 			"offset",
 			summaryLabels,
-			nil,
 		),
-		total: prometheus.NewDesc(
+		total: descs.gauge(
 			prometheus.BuildFQName(system, connzEndpoint, "total"),
 			"total",
 			summaryLabels,
-			nil,
 		),
-		limit: prometheus.NewDesc(
+		limit: descs.gauge(
 			prometheus.BuildFQName(system, connzEndpoint, "limit"),
 			"limit",
 			summaryLabels,
-			nil,
 		),
-		totalPendingBytes: prometheus.NewDesc(
+		totalPendingBytes: descs.gauge(
 			prometheus.BuildFQName(system, connzEndpoint, "pending_bytes"),
 			"pending_bytes",
 			summaryLabels,
-			nil,
 		),
-		totalSubscriptions: prometheus.NewDesc(
+		totalSubscriptions: descs.gauge(
 			prometheus.BuildFQName(system, connzEndpoint, "subscriptions"),
 			"subscriptions",
 			summaryLabels,
-			nil,
 		),
-		totalInBytes: prometheus.NewDesc(
+		totalInBytes: descs.counter(
 			prometheus.BuildFQName(system, connzEndpoint, "in_bytes"),
 			"in_bytes",
 			summaryLabels,
-			nil,
 		),
-		totalOutBytes: prometheus.NewDesc(
+		totalOutBytes: descs.counter(
 			prometheus.BuildFQName(system, connzEndpoint, "out_bytes"),
 			"out_bytes",
 			summaryLabels,
-			nil,
 		),
-		totalInMsgs: prometheus.NewDesc(
+		totalInMsgs: descs.counter(
 			prometheus.BuildFQName(system, connzEndpoint, "in_msgs"),
 			"in_msgs",
 			summaryLabels,
-			nil,
 		),
-		totalOutMsgs: prometheus.NewDesc(
+		totalOutMsgs: descs.counter(
 			prometheus.BuildFQName(system, connzEndpoint, "out_msgs"),
 			"out_msgs",
 			summaryLabels,
-			nil,
 		),
 	}
 }
@@ -140,76 +145,66 @@ func createConnzDetailedCollector(system string) *connzCollector {
 	connzCollector := createConnzCollector(system)
 	detailLabels := []string{"server_id", "cid", "kind", "type", "ip", "port", "name", "lang",
 		"version", "tls_version", "tls_cipher_suite"}
-	connzCollector.pendingBytes = prometheus.NewDesc(
+	connzCollector.pendingBytes = connzCollector.metricDescs.gauge(
 		prometheus.BuildFQName(system, connzEndpoint, "pending_bytes"),
 		"pending_bytes",
 		detailLabels,
-		nil,
 	)
-	connzCollector.subscriptions = prometheus.NewDesc(
+	connzCollector.subscriptions = connzCollector.metricDescs.gauge(
 		prometheus.BuildFQName(system, connzEndpoint, "subscriptions"),
 		"subscriptions",
 		detailLabels,
-		nil,
 	)
-	connzCollector.inBytes = prometheus.NewDesc(
+	connzCollector.inBytes = connzCollector.metricDescs.counter(
 		prometheus.BuildFQName(system, connzEndpoint, "in_bytes"),
 		"in_bytes",
 		detailLabels,
-		nil,
 	)
-	connzCollector.outBytes = prometheus.NewDesc(
+	connzCollector.outBytes = connzCollector.metricDescs.counter(
 		prometheus.BuildFQName(system, connzEndpoint, "out_bytes"),
 		"out_bytes",
 		detailLabels,
-		nil,
 	)
-	connzCollector.inMsgs = prometheus.NewDesc(
+	connzCollector.inMsgs = connzCollector.metricDescs.counter(
 		prometheus.BuildFQName(system, connzEndpoint, "in_msgs"),
 		"in_msgs",
 		detailLabels,
-		nil,
 	)
-	connzCollector.outMsgs = prometheus.NewDesc(
+	connzCollector.outMsgs = connzCollector.metricDescs.counter(
 		prometheus.BuildFQName(system, connzEndpoint, "out_msgs"),
 		"out_msgs",
 		detailLabels,
-		nil,
 	)
-	connzCollector.start = prometheus.NewDesc(
+	connzCollector.start = connzCollector.metricDescs.untyped(
 		prometheus.BuildFQName(system, connzEndpoint, "start"),
 		"epoch time at which the connection was started",
 		detailLabels,
-		nil,
 	)
-	connzCollector.lastActivity = prometheus.NewDesc(
+	connzCollector.lastActivity = connzCollector.metricDescs.untyped(
 		prometheus.BuildFQName(system, connzEndpoint, "last_activity"),
 		"epoch time at which the last activity was registred",
 		detailLabels,
-		nil,
 	)
-	connzCollector.rtt = prometheus.NewDesc(
+	connzCollector.rtt = connzCollector.metricDescs.gauge(
 		prometheus.BuildFQName(system, connzEndpoint, "rtt"),
 		"response time latency in microseconds",
 		detailLabels,
-		nil,
 	)
-	connzCollector.uptime = prometheus.NewDesc(
+	connzCollector.uptime = connzCollector.metricDescs.untyped(
 		prometheus.BuildFQName(system, connzEndpoint, "uptime"),
 		"uptime duration in milliseconds",
 		detailLabels,
-		nil,
 	)
-	connzCollector.idle = prometheus.NewDesc(
+	connzCollector.idle = connzCollector.metricDescs.gauge(
 		prometheus.BuildFQName(system, connzEndpoint, "idle"),
 		"idle time duration in milliseconds",
 		detailLabels,
-		nil,
 	)
 	return connzCollector
 }
 
-func newConnzCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
+func newConnzCollector(system, endpoint string, servers []*CollectedServer,
+	opts *CollectorOptions) prometheus.Collector {
 	var nc *connzCollector
 	if endpoint == connzDetailedEndpoint {
 		nc = createConnzDetailedCollector(system)
@@ -217,6 +212,42 @@ func newConnzCollector(system, endpoint string, servers []*CollectedServer) prom
 	} else {
 		nc = createConnzCollector(system)
 	}
+	nc.httpClient = &http.Client{Transport: opts.Transport}
+	nc.idleTopN = opts.ConnzIdleTopN
+	nc.streamThreshold = opts.ConnzStreamThreshold
+	nc.slowConsumersAccount = opts.ConnzSlowConsumersByAccount
+	nc.accountKinds = opts.ConnzAccountKinds
+	nc.tlsVersionCounts = opts.ConnzTLSVersions
+
+	// Only the optional metrics enabled are described.
+	if nc.idleTopN > 0 {
+		nc.connIdle = nc.metricDescs.gauge(
+			prometheus.BuildFQName(system, connzEndpoint, "connection_idle_seconds"),
+			"idle time in seconds of the connections idle the longest",
+			[]string{"server_id", "cid", "name"},
+		)
+	}
+	if nc.tlsVersionCounts {
+		nc.tlsVersions = nc.metricDescs.gauge(
+			prometheus.BuildFQName(system, connzEndpoint, "tls_version_connections"),
+			"number of connections by TLS version",
+			[]string{"server_id", "version"},
+		)
+	}
+	if nc.slowConsumersAccount {
+		nc.slowConsumers = nc.metricDescs.gauge(
+			prometheus.BuildFQName(system, connzEndpoint, "account_slow_consumers"),
+			"number of recently closed connections of an account which were slow consumers",
+			[]string{"server_id", "account"},
+		)
+	}
+	if nc.accountKinds {
+		nc.accountConnections = nc.metricDescs.gauge(
+			prometheus.BuildFQName(system, connzEndpoint, "account_connections"),
+			"number of connections of an account by kind",
+			[]string{"server_id", "account", "kind"},
+		)
+	}
 	nc.servers = make([]*CollectedServer, len(servers))
 	for i, s := range servers {
 		nc.servers[i] = &CollectedServer{
@@ -227,44 +258,29 @@ func newConnzCollector(system, endpoint string, servers []*CollectedServer) prom
 	return nc
 }
 
-func (nc *connzCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nc.limit
-}
-
 // Collect gathers the server connz metrics.
 func (nc *connzCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, server := range nc.servers {
 		var resp Connz
-		if err := getMetricURL(nc.httpClient, server.URL, &resp); err != nil {
-			Debugf("ignoring server %s: %v", server.ID, err)
-			continue
-		}
-
 		var pendingBytes, subscriptions, inBytes, outBytes, inMsgs, outMsgs float64
-		for _, conn := range resp.Connections {
+		versions := make(map[string]float64)
+		n, err := nc.fetchConnz(server.URL, &resp, func(conn *ConnzConnection) {
 			pendingBytes += conn.PendingBytes
 			subscriptions += conn.Subscriptions
 			inBytes += conn.InBytes
 			outBytes += conn.OutBytes
 			inMsgs += conn.InMsgs
 			outMsgs += conn.OutMsgs
+			if nc.tlsVersionCounts && conn.TLSVersion != "" {
+				versions[conn.TLSVersion]++
+			}
 			if nc.detailed {
-				detailLabelValues := []string{server.ID, conn.Cid, conn.Kind, conn.Type, conn.IP, conn.Port,
-					conn.Name, conn.Lang, conn.Version, conn.TLSVersion, conn.TLSCipherSuite}
-				ch <- prometheus.MustNewConstMetric(nc.pendingBytes, prometheus.GaugeValue, conn.PendingBytes, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.subscriptions, prometheus.GaugeValue, conn.Subscriptions,
-					detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.inBytes, prometheus.CounterValue, conn.InBytes, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.outBytes, prometheus.CounterValue, conn.OutBytes, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.inMsgs, prometheus.CounterValue, conn.InMsgs, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.outMsgs, prometheus.CounterValue, conn.OutMsgs, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.start, prometheus.UntypedValue, conn.Start, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.lastActivity, prometheus.UntypedValue, conn.LastActivity,
-					detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.rtt, prometheus.GaugeValue, conn.Rtt, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.uptime, prometheus.UntypedValue, conn.Uptime, detailLabelValues...)
-				ch <- prometheus.MustNewConstMetric(nc.idle, prometheus.GaugeValue, conn.Idle, detailLabelValues...)
+				nc.collectDetailed(server, conn, ch)
 			}
+		})
+		if err != nil {
+			Debugf("ignoring server %s: %v", server.ID, err)
+			continue
 		}
 
 		ch <- prometheus.MustNewConstMetric(nc.numConnections, prometheus.GaugeValue, resp.NumConnections, server.ID)
@@ -277,6 +293,287 @@ func (nc *connzCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(nc.totalOutBytes, prometheus.CounterValue, outBytes, server.ID)
 		ch <- prometheus.MustNewConstMetric(nc.totalInMsgs, prometheus.CounterValue, inMsgs, server.ID)
 		ch <- prometheus.MustNewConstMetric(nc.totalOutMsgs, prometheus.CounterValue, outMsgs, server.ID)
+
+		if nc.tlsVersionCounts {
+			nc.collectTLSVersions(server, &resp, n, versions, ch)
+		}
+
+		if nc.idleTopN > 0 {
+			nc.collectIdle(server, ch)
+		}
+
+		if nc.slowConsumersAccount {
+			nc.collectSlowConsumers(server, ch)
+		}
+
+		if nc.accountKinds {
+			nc.collectAccountKinds(server, ch)
+		}
+	}
+}
+
+// collectDetailed reports the metrics of a single connection.
+func (nc *connzCollector) collectDetailed(server *CollectedServer, conn *ConnzConnection, ch chan<- prometheus.Metric) {
+	detailLabelValues := []string{server.ID, conn.Cid, conn.Kind, conn.Type, conn.IP, conn.Port,
+		conn.Name, conn.Lang, conn.Version, conn.TLSVersion, conn.TLSCipherSuite}
+	ch <- prometheus.MustNewConstMetric(nc.pendingBytes, prometheus.GaugeValue, conn.PendingBytes, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.subscriptions, prometheus.GaugeValue, conn.Subscriptions,
+		detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.inBytes, prometheus.CounterValue, conn.InBytes, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.outBytes, prometheus.CounterValue, conn.OutBytes, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.inMsgs, prometheus.CounterValue, conn.InMsgs, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.outMsgs, prometheus.CounterValue, conn.OutMsgs, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.start, prometheus.UntypedValue, conn.Start, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.lastActivity, prometheus.UntypedValue, conn.LastActivity,
+		detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.rtt, prometheus.GaugeValue, conn.Rtt, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.uptime, prometheus.UntypedValue, conn.Uptime, detailLabelValues...)
+	ch <- prometheus.MustNewConstMetric(nc.idle, prometheus.GaugeValue, conn.Idle, detailLabelValues...)
+}
+
+// fetchConnz gets a page of connections of a server, calling handle for
+// each of them, and returns the number of connections of the page. The
+// connections of a response larger than the stream threshold are decoded
+// one at a time as they are read, and are not kept in resp.
+func (nc *connzCollector) fetchConnz(url string, resp *Connz, handle func(*ConnzConnection)) (int, error) {
+	if nc.streamThreshold <= 0 || isSystemURL(url) {
+		if err := getMetricURL(nc.httpClient, url, resp); err != nil {
+			return 0, err
+		}
+		for i := range resp.Connections {
+			handle(&resp.Connections[i])
+		}
+		return len(resp.Connections), nil
+	}
+	n, err := nc.streamConnz(url, resp, handle)
+	recordFetchResult(url, err)
+	return n, err
+}
+
+// streamConnz gets a page of connections of a server like fetchConnz,
+// streaming a response larger than the stream threshold.
+func (nc *connzCollector) streamConnz(url string, resp *Connz, handle func(*ConnzConnection)) (int, error) {
+	httpResp, err := httpGet(nc.httpClient, url)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResp.Body.Close()
+	head, err := io.ReadAll(io.LimitReader(httpResp.Body, nc.streamThreshold+1))
+	if err != nil {
+		return 0, err
+	}
+	if int64(len(head)) <= nc.streamThreshold {
+		Tracef("Retrieved metric result:\n%s\n", string(head))
+		recordResponse(url, head)
+		if err := json.Unmarshal(head, resp); err != nil {
+			return 0, err
+		}
+		for i := range resp.Connections {
+			handle(&resp.Connections[i])
+		}
+		return len(resp.Connections), nil
+	}
+	Tracef("Streaming metric result larger than %d bytes from %s", nc.streamThreshold, url)
+	return decodeConnzStream(io.MultiReader(bytes.NewReader(head), httpResp.Body), resp, handle)
+}
+
+// decodeConnzStream decodes a connz response, calling handle for each of
+// its connections instead of storing them in resp.
+func decodeConnzStream(r io.Reader, resp *Connz, handle func(*ConnzConnection)) (int, error) {
+	fields := map[string]*float64{
+		"num_connections": &resp.NumConnections,
+		"total":           &resp.Total,
+		"offset":          &resp.Offset,
+		"limit":           &resp.Limit,
+	}
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if tok != json.Delim('{') {
+		return 0, fmt.Errorf("unexpected connz token %v", tok)
+	}
+	var n int
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return n, err
+		}
+		key, _ := tok.(string)
+		if field, ok := fields[key]; ok {
+			if err := dec.Decode(field); err != nil {
+				return n, err
+			}
+			continue
+		}
+		if key != "connections" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return n, err
+			}
+			continue
+		}
+		if tok, err = dec.Token(); err != nil {
+			return n, err
+		}
+		if tok == nil {
+			continue
+		}
+		if tok != json.Delim('[') {
+			return n, fmt.Errorf("unexpected connz connections token %v", tok)
+		}
+		for dec.More() {
+			var conn ConnzConnection
+			if err := dec.Decode(&conn); err != nil {
+				return n, err
+			}
+			handle(&conn)
+			n++
+		}
+		if _, err := dec.Token(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// collectTLSVersions reports the number of connections using each TLS
+// version on a server, going through all the pages of connections after
+// the first one, whose counted connections are in versions already.
+func (nc *connzCollector) collectTLSVersions(server *CollectedServer, first *Connz, counted int,
+	versions map[string]float64, ch chan<- prometheus.Metric) {
+	count := func(conn *ConnzConnection) {
+		if conn.TLSVersion != "" {
+			versions[conn.TLSVersion]++
+		}
+	}
+
+	offset := first.Offset + float64(counted)
+	for counted > 0 && offset < first.Total {
+		var resp Connz
+		url := fmt.Sprintf("%s?offset=%d&limit=%d", server.URL, int(offset), int(first.Limit))
+		page, err := nc.fetchConnz(url, &resp, count)
+		if err != nil {
+			Debugf("ignoring TLS versions of server %s: %v", server.ID, err)
+			return
+		}
+		if page == 0 {
+			break
+		}
+		offset += float64(page)
+	}
+
+	for version, n := range versions {
+		ch <- prometheus.MustNewConstMetric(nc.tlsVersions, prometheus.GaugeValue, n, server.ID, version)
+	}
+}
+
+// collectSlowConsumers reports the number of slow consumers of each account
+// on a server, going through all the pages of the closed connections the
+// server keeps track of.
+func (nc *connzCollector) collectSlowConsumers(server *CollectedServer, ch chan<- prometheus.Metric) {
+	var accounts []string
+	slow := make(map[string]float64)
+	count := func(conn *ConnzConnection) {
+		if !strings.HasPrefix(conn.Reason, "Slow Consumer") {
+			return
+		}
+		if _, ok := slow[conn.Account]; !ok {
+			accounts = append(accounts, conn.Account)
+		}
+		slow[conn.Account]++
+	}
+
+	if err := nc.fetchAllConnz(server.URL+"?state=closed&auth=true", count); err != nil {
+		Debugf("ignoring slow consumers of server %s: %v", server.ID, err)
+		return
+	}
+
+	for _, account := range accounts {
+		ch <- prometheus.MustNewConstMetric(nc.slowConsumers, prometheus.GaugeValue, slow[account],
+			server.ID, account)
+	}
+}
+
+// connectionKinds are the names of the kinds of connections reported by
+// connz, by their connz kind.
+var connectionKinds = map[string]string{
+	"Client":   "client",
+	"Leafnode": "leaf",
+	"Router":   "route",
+}
+
+// collectAccountKinds reports the number of connections of each account on a
+// server by kind, going through all the pages of its connections.
+func (nc *connzCollector) collectAccountKinds(server *CollectedServer, ch chan<- prometheus.Metric) {
+	type accountKind struct{ account, kind string }
+	var keys []accountKind
+	conns := make(map[accountKind]float64)
+	count := func(conn *ConnzConnection) {
+		kind, ok := connectionKinds[conn.Kind]
+		if !ok {
+			kind = strings.ToLower(conn.Kind)
+		}
+		key := accountKind{conn.Account, kind}
+		if _, ok := conns[key]; !ok {
+			keys = append(keys, key)
+		}
+		conns[key]++
+	}
+
+	if err := nc.fetchAllConnz(server.URL+"?auth=true", count); err != nil {
+		Debugf("ignoring connections by account of server %s: %v", server.ID, err)
+		return
+	}
+
+	for _, key := range keys {
+		ch <- prometheus.MustNewConstMetric(nc.accountConnections, prometheus.GaugeValue, conns[key],
+			server.ID, key.account, key.kind)
+	}
+}
+
+// fetchAllConnz handles the connections of all the pages of a connz url,
+// which must have a query.
+func (nc *connzCollector) fetchAllConnz(url string, handle func(*ConnzConnection)) error {
+	var offset int
+	for {
+		var resp Connz
+		n, err := nc.fetchConnz(fmt.Sprintf("%s&offset=%d", url, offset), &resp, handle)
+		if err != nil {
+			return err
+		}
+		offset += n
+		if n == 0 || float64(offset) >= resp.Total {
+			return nil
+		}
+	}
+}
+
+// collectIdle reports the idle time of the connections that have been idle
+// the longest on a server.
+func (nc *connzCollector) collectIdle(server *CollectedServer, ch chan<- prometheus.Metric) {
+	var resp Connz
+	url := fmt.Sprintf("%s?sort=idle&limit=%d", server.URL, nc.idleTopN)
+	if err := getMetricURL(nc.httpClient, url, &resp); err != nil {
+		Debugf("ignoring idle connections of server %s: %v", server.ID, err)
+		return
+	}
+
+	// The server sorts and limits the connections already, do it again
+	// in case the sort option is not supported by the server.
+	conns := resp.Connections
+	sort.SliceStable(conns, func(i, j int) bool {
+		return conns[i].Idle > conns[j].Idle
+	})
+	if len(conns) > nc.idleTopN {
+		conns = conns[:nc.idleTopN]
+	}
+	for _, conn := range conns {
+		if conn.Idle < 0 {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(nc.connIdle, prometheus.GaugeValue, conn.Idle/1000,
+			server.ID, conn.Cid, conn.Name)
 	}
 }
 
@@ -312,6 +609,8 @@ type ConnzConnection struct {
 	Version        string  `json:"version"`
 	TLSVersion     string  `json:"tls_version"`
 	TLSCipherSuite string  `json:"tls_cipher_suite"`
+	Account        string  `json:"account"`
+	Reason         string  `json:"reason"`
 }
 
 // UnmarshalJSON converts JSON string to struct. This is required as we want to
@@ -390,6 +689,12 @@ func (c *ConnzConnection) UnmarshalJSON(data []byte) error {
 	if val, exists := connection["tls_cipher_suite"]; exists {
 		c.TLSCipherSuite = val.(string)
 	}
+	if val, exists := connection["account"]; exists {
+		c.Account = val.(string)
+	}
+	if val, exists := connection["reason"]; exists {
+		c.Reason = val.(string)
+	}
 	return nil
 }
 
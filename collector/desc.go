@@ -0,0 +1,96 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricDesc describes a metric reported by a collector, along with its
+// name, help, type and labels, which a prometheus description does not
+// expose.
+type MetricDesc struct {
+	Desc   *prometheus.Desc
+	Name   string
+	Help   string
+	Type   prometheus.ValueType
+	Labels []string
+}
+
+// NewMetricDesc returns the description of a metric of the given type.
+func NewMetricDesc(valueType prometheus.ValueType, fqName, help string, labels []string) *MetricDesc {
+	return &MetricDesc{
+		Desc:   prometheus.NewDesc(fqName, help, labels, nil),
+		Name:   fqName,
+		Help:   help,
+		Type:   valueType,
+		Labels: labels,
+	}
+}
+
+// MetricDescriber is implemented by the collectors describing the metrics
+// they report along with their metadata.
+type MetricDescriber interface {
+	// MetricDescs returns the descriptions of all the metrics the collector
+	// may report, whether or not its servers are available.  A metric
+	// reported with several sets of labels has a description for each.
+	MetricDescs() []*MetricDesc
+}
+
+// DescribeMetricDescs sends the descriptions to the channel, only the first
+// one of each name, which a collector cannot describe with two sets of
+// labels.
+func DescribeMetricDescs(descs []*MetricDesc, ch chan<- *prometheus.Desc) {
+	seen := make(map[string]struct{}, len(descs))
+	for _, d := range descs {
+		if _, ok := seen[d.Name]; ok {
+			continue
+		}
+		seen[d.Name] = struct{}{}
+		ch <- d.Desc
+	}
+}
+
+// metricDescs creates the descriptions of the metrics of a collector and
+// describes all of them.
+type metricDescs struct {
+	descs []*MetricDesc
+}
+
+func (md *metricDescs) add(d *MetricDesc) *prometheus.Desc {
+	md.descs = append(md.descs, d)
+	return d.Desc
+}
+
+func (md *metricDescs) gauge(fqName, help string, labels []string) *prometheus.Desc {
+	return md.add(NewMetricDesc(prometheus.GaugeValue, fqName, help, labels))
+}
+
+func (md *metricDescs) counter(fqName, help string, labels []string) *prometheus.Desc {
+	return md.add(NewMetricDesc(prometheus.CounterValue, fqName, help, labels))
+}
+
+func (md *metricDescs) untyped(fqName, help string, labels []string) *prometheus.Desc {
+	return md.add(NewMetricDesc(prometheus.UntypedValue, fqName, help, labels))
+}
+
+// Describe implements prometheus.Collector.
+func (md *metricDescs) Describe(ch chan<- *prometheus.Desc) {
+	DescribeMetricDescs(md.descs, ch)
+}
+
+// MetricDescs implements MetricDescriber.
+func (md *metricDescs) MetricDescs() []*MetricDesc {
+	return md.descs
+}
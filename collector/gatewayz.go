@@ -29,6 +29,7 @@ func isGatewayzEndpoint(system, endpoint string) bool {
 
 type gatewayzCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient       *http.Client
 	servers          []*CollectedServer
@@ -36,11 +37,13 @@ type gatewayzCollector struct {
 	inboundGateways  *gateway
 }
 
-func newGatewayzCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
+func newGatewayzCollector(system, endpoint string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
+	descs := &metricDescs{}
 	nc := &gatewayzCollector{
-		httpClient:       http.DefaultClient,
-		outboundGateways: newGateway(system, endpoint, "outbound_gateway"),
-		inboundGateways:  newGateway(system, endpoint, "inbound_gateway"),
+		metricDescs:      descs,
+		httpClient:       &http.Client{Transport: transport},
+		outboundGateways: newGateway(descs, system, endpoint, "outbound_gateway"),
+		inboundGateways:  newGateway(descs, system, endpoint, "inbound_gateway"),
 	}
 	nc.servers = make([]*CollectedServer, len(servers))
 	for i, s := range servers {
@@ -52,11 +55,6 @@ func newGatewayzCollector(system, endpoint string, servers []*CollectedServer) p
 	return nc
 }
 
-func (nc *gatewayzCollector) Describe(ch chan<- *prometheus.Desc) {
-	nc.outboundGateways.Describe(ch)
-	nc.inboundGateways.Describe(ch)
-}
-
 // Collect gathers the server gatewayz metrics.
 func (nc *gatewayzCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, server := range nc.servers {
@@ -92,89 +90,73 @@ type gateway struct {
 	connSubscriptions *prometheus.Desc
 }
 
-func newGateway(system, endpoint, gwType string) *gateway {
+func newGateway(descs *metricDescs, system, endpoint, gwType string) *gateway {
 	gw := &gateway{
-		configured: prometheus.NewDesc(
+		configured: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_configured"),
 			"configured",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connStart: prometheus.NewDesc(
+		),
+		connStart: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_start_time_seconds"),
 			"conn_start_time_seconds",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connLastActivity: prometheus.NewDesc(
+		),
+		connLastActivity: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_last_activity_seconds"),
 			"conn_last_activity_seconds",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connUptime: prometheus.NewDesc(
+		),
+		connUptime: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_uptime_seconds"),
 			"conn_uptime_seconds",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connIdle: prometheus.NewDesc(
+		),
+		connIdle: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_idle_seconds"),
 			"conn_idle_seconds",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connRtt: prometheus.NewDesc(
+		),
+		connRtt: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_rtt"),
 			"rtt",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connPendingBytes: prometheus.NewDesc(
+		),
+		connPendingBytes: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_pending_bytes"),
 			"pending_bytes",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connInMsgs: prometheus.NewDesc(
+		),
+		connInMsgs: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_in_msgs"),
 			"in_msgs",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connOutMsgs: prometheus.NewDesc(
+		),
+		connOutMsgs: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_out_msgs"),
 			"out_msgs",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connInBytes: prometheus.NewDesc(
+		),
+		connInBytes: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_in_bytes"),
 			"in_bytes",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connOutBytes: prometheus.NewDesc(
+		),
+		connOutBytes: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_out_bytes"),
 			"out_bytes",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
-		connSubscriptions: prometheus.NewDesc(
+		),
+		connSubscriptions: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, gwType+"_conn_subscriptions"),
 			"subscriptions",
 			[]string{"gateway_name", "cid", "remote_gateway_name", "server_id"},
-			nil),
+		),
 	}
 
 	return gw
 }
 
-// Describe
-func (gw *gateway) Describe(ch chan<- *prometheus.Desc) {
-	ch <- gw.configured
-	ch <- gw.connStart
-	ch <- gw.connLastActivity
-	ch <- gw.connUptime
-	ch <- gw.connIdle
-	ch <- gw.connRtt
-	ch <- gw.connPendingBytes
-	ch <- gw.connInMsgs
-	ch <- gw.connOutMsgs
-	ch <- gw.connInBytes
-	ch <- gw.connOutBytes
-	ch <- gw.connSubscriptions
-}
-
 func (gw *gateway) Collect(server *CollectedServer, lgwName, rgwName string,
 	rgw *RemoteGatewayz, ch chan<- prometheus.Metric) {
 
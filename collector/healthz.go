@@ -27,6 +27,7 @@ func isHealthzEndpoint(system, endpoint string) bool {
 
 type healthzCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient *http.Client
 	servers    []*CollectedServer
@@ -34,14 +35,15 @@ type healthzCollector struct {
 	status *prometheus.Desc
 }
 
-func newHealthzCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
+func newHealthzCollector(system, endpoint string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
+	descs := &metricDescs{}
 	nc := &healthzCollector{
-		httpClient: http.DefaultClient,
-		status: prometheus.NewDesc(
+		metricDescs: descs,
+		httpClient:  &http.Client{Transport: transport},
+		status: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "status"),
 			"status",
 			[]string{"server_id"},
-			nil,
 		),
 	}
 
@@ -56,10 +58,6 @@ func newHealthzCollector(system, endpoint string, servers []*CollectedServer) pr
 	return nc
 }
 
-func (nc *healthzCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nc.status
-}
-
 // Collect gathers the server healthz metrics.
 func (nc *healthzCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, server := range nc.servers {
@@ -0,0 +1,52 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const heapEndpoint = "heap"
+
+func isHeapEndpoint(system, endpoint string) bool {
+	return system == ExporterSystem && endpoint == heapEndpoint
+}
+
+// heapCollector reports the heap memory in use by the exporter, under a
+// name which cannot be mistaken for the memory of the servers.
+type heapCollector struct {
+	*metricDescs
+	inuse *prometheus.Desc
+}
+
+func newHeapCollector() prometheus.Collector {
+	descs := &metricDescs{}
+	return &heapCollector{
+		metricDescs: descs,
+		inuse: descs.gauge(
+			prometheus.BuildFQName(ExporterSystem, "heap", "inuse_bytes"),
+			"Bytes of heap memory in use by the exporter",
+			nil,
+		),
+	}
+}
+
+// Collect reads the memory statistics of the exporter.
+func (nc *heapCollector) Collect(ch chan<- prometheus.Metric) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	ch <- prometheus.MustNewConstMetric(nc.inuse, prometheus.GaugeValue, float64(ms.HeapInuse))
+}
@@ -16,6 +16,8 @@ package collector
 
 import (
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,18 +28,36 @@ import (
 
 type jszCollector struct {
 	sync.Mutex
+	*metricDescs
 	httpClient *http.Client
 	servers    []*CollectedServer
 	endpoint   string
+	domain     string
+	subjects   bool
+
+	// metaLeaders keeps track of the meta leader of each cluster, and of
+	// when it was first seen leading.
+	metaLeaders map[string]metaLeader
 
 	// JetStream server stats
-	disabled   *prometheus.Desc
-	streams    *prometheus.Desc
-	consumers  *prometheus.Desc
-	messages   *prometheus.Desc
-	bytes      *prometheus.Desc
-	maxMemory  *prometheus.Desc
-	maxStorage *prometheus.Desc
+	disabled    *prometheus.Desc
+	streams     *prometheus.Desc
+	consumers   *prometheus.Desc
+	messages    *prometheus.Desc
+	bytes       *prometheus.Desc
+	maxMemory   *prometheus.Desc
+	maxStorage  *prometheus.Desc
+	apiInflight *prometheus.Desc
+
+	reservedMemory  *prometheus.Desc
+	reservedStorage *prometheus.Desc
+
+	metaLeaderChange    *prometheus.Desc
+	clusterExpectedSize *prometheus.Desc
+	clusterCurrentSize  *prometheus.Desc
+
+	// Account stats
+	accountStreamsByReplicas *prometheus.Desc
 
 	// Stream stats
 	streamMessages      *prometheus.Desc
@@ -45,6 +65,13 @@ type jszCollector struct {
 	streamFirstSeq      *prometheus.Desc
 	streamLastSeq       *prometheus.Desc
 	streamConsumerCount *prometheus.Desc
+	streamNumDeleted    *prometheus.Desc
+	streamLostMessages  *prometheus.Desc
+	streamSubject       *prometheus.Desc
+	streamMaxLag        *prometheus.Desc
+	streamMaxConsumers  *prometheus.Desc
+	streamAvgMsgBytes   *prometheus.Desc
+	streamOldestMsg     *prometheus.Desc
 
 	// Consumer stats
 	consumerDeliveredConsumerSeq *prometheus.Desc
@@ -55,13 +82,37 @@ type jszCollector struct {
 	consumerNumPending           *prometheus.Desc
 	consumerAckFloorStreamSeq    *prometheus.Desc
 	consumerAckFloorConsumerSeq  *prometheus.Desc
+	consumerIdle                 *prometheus.Desc
+	consumerInactiveThreshold    *prometheus.Desc
+	consumerIsPush               *prometheus.Desc
+}
+
+// jszResponse is the /jsz response, keeping track of the API stats which
+// are only reported by some servers.
+type jszResponse struct {
+	nats.JSInfo
+	API *jszAPIStats `json:"api,omitempty"`
+}
+
+type metaLeader struct {
+	name  string
+	since time.Time
+}
+
+// jszAPIStats are the JetStream API stats, where inflight is left out by
+// the server when there are no inflight requests.
+type jszAPIStats struct {
+	Total    uint64 `json:"total"`
+	Errors   uint64 `json:"errors"`
+	Inflight uint64 `json:"inflight,omitempty"`
 }
 
 func isJszEndpoint(system string) bool {
 	return system == JetStreamSystem
 }
 
-func newJszCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
+func newJszCollector(system, endpoint string, servers []*CollectedServer,
+	opts *CollectorOptions) prometheus.Collector {
 	serverLabels := []string{"server_id", "server_name", "cluster", "domain", "meta_leader", "is_meta_leader"}
 
 	var streamLabels []string
@@ -72,157 +123,257 @@ func newJszCollector(system, endpoint string, servers []*CollectedServer) promet
 	streamLabels = append(streamLabels, "stream_leader")
 	streamLabels = append(streamLabels, "is_stream_leader")
 
+	var accountReplicasLabels []string
+	accountReplicasLabels = append(accountReplicasLabels, serverLabels...)
+	accountReplicasLabels = append(accountReplicasLabels, "account")
+	accountReplicasLabels = append(accountReplicasLabels, "account_id")
+	accountReplicasLabels = append(accountReplicasLabels, "replicas")
+
+	var streamSubjectLabels []string
+	streamSubjectLabels = append(streamSubjectLabels, streamLabels...)
+	streamSubjectLabels = append(streamSubjectLabels, "subject")
+
 	var consumerLabels []string
 	consumerLabels = append(consumerLabels, streamLabels...)
 	consumerLabels = append(consumerLabels, "consumer_name")
 	consumerLabels = append(consumerLabels, "consumer_leader")
 	consumerLabels = append(consumerLabels, "is_consumer_leader")
 	consumerLabels = append(consumerLabels, "consumer_desc")
+	consumerLabels = append(consumerLabels, "type")
 
+	descs := &metricDescs{}
 	nc := &jszCollector{
+		metricDescs: descs,
 		httpClient: &http.Client{
-			Timeout: 5 * time.Second,
+			Transport: opts.Transport,
+			Timeout:   5 * time.Second,
 		},
 		endpoint: endpoint,
+		domain:   opts.JszDomain,
+		subjects: opts.JszStreamSubjects,
 		// jetstream_disabled
-		disabled: prometheus.NewDesc(
+		disabled: descs.gauge(
 			prometheus.BuildFQName(system, "server", "jetstream_disabled"),
 			"JetStream disabled or not",
 			serverLabels,
-			nil,
 		),
 		// jetstream_stream_total_messages
-		streams: prometheus.NewDesc(
+		streams: descs.gauge(
 			prometheus.BuildFQName(system, "server", "total_streams"),
 			"Total number of streams in JetStream",
 			serverLabels,
-			nil,
 		),
 		// jetstream_server_total_consumers
-		consumers: prometheus.NewDesc(
+		consumers: descs.gauge(
 			prometheus.BuildFQName(system, "server", "total_consumers"),
 			"Total number of consumers in JetStream",
 			serverLabels,
-			nil,
 		),
 		// jetstream_server_total_messages
-		messages: prometheus.NewDesc(
+		messages: descs.gauge(
 			prometheus.BuildFQName(system, "server", "total_messages"),
 			"Total number of stored messages in JetStream",
 			serverLabels,
-			nil,
 		),
 		// jetstream_server_total_message_bytes
-		bytes: prometheus.NewDesc(
+		bytes: descs.gauge(
 			prometheus.BuildFQName(system, "server", "total_message_bytes"),
 			"Total number of bytes stored in JetStream",
 			serverLabels,
-			nil,
 		),
 		// jetstream_server_max_memory
-		maxMemory: prometheus.NewDesc(
+		maxMemory: descs.gauge(
 			prometheus.BuildFQName(system, "server", "max_memory"),
 			"JetStream Max Memory",
 			serverLabels,
-			nil,
 		),
 		// jetstream_server_max_storage
-		maxStorage: prometheus.NewDesc(
+		maxStorage: descs.gauge(
 			prometheus.BuildFQName(system, "server", "max_storage"),
 			"JetStream Max Storage",
 			serverLabels,
-			nil,
+		),
+		// jetstream_server_reserved_memory
+		reservedMemory: descs.gauge(
+			prometheus.BuildFQName(system, "server", "reserved_memory"),
+			"JetStream memory reserved by the streams",
+			serverLabels,
+		),
+		// jetstream_server_reserved_storage
+		reservedStorage: descs.gauge(
+			prometheus.BuildFQName(system, "server", "reserved_storage"),
+			"JetStream storage reserved by the streams",
+			serverLabels,
+		),
+		// jetstream_api_inflight
+		apiInflight: descs.gauge(
+			prometheus.BuildFQName(system, "api", "inflight"),
+			"Number of JetStream API requests being processed",
+			serverLabels,
+		),
+		// jetstream_meta_leader_last_change_timestamp_seconds
+		metaLeaderChange: descs.gauge(
+			prometheus.BuildFQName(system, "meta", "leader_last_change_timestamp_seconds"),
+			"Time at which the current JetStream meta leader was first seen leading",
+			serverLabels,
+		),
+		// jetstream_cluster_expected_size
+		clusterExpectedSize: descs.gauge(
+			prometheus.BuildFQName(system, "cluster", "expected_size"),
+			"Number of servers the JetStream meta cluster is expected to have",
+			serverLabels,
+		),
+		// jetstream_cluster_current_size
+		clusterCurrentSize: descs.gauge(
+			prometheus.BuildFQName(system, "cluster", "current_size"),
+			"Number of servers of the JetStream meta cluster which are online",
+			serverLabels,
 		),
 		// jetstream_stream_total_messages
-		streamMessages: prometheus.NewDesc(
+		streamMessages: descs.gauge(
 			prometheus.BuildFQName(system, "stream", "total_messages"),
 			"Total number of messages from a stream",
 			streamLabels,
-			nil,
 		),
 		// jetstream_stream_total_bytes
-		streamBytes: prometheus.NewDesc(
+		streamBytes: descs.gauge(
 			prometheus.BuildFQName(system, "stream", "total_bytes"),
 			"Total stored bytes from a stream",
 			streamLabels,
-			nil,
 		),
 		// jetstream_stream_state_first_seq
-		streamFirstSeq: prometheus.NewDesc(
+		streamFirstSeq: descs.gauge(
 			prometheus.BuildFQName(system, "stream", "first_seq"),
 			"First sequence from a stream",
 			streamLabels,
-			nil,
 		),
 		// jetstream_stream_state_last_seq
-		streamLastSeq: prometheus.NewDesc(
+		streamLastSeq: descs.gauge(
 			prometheus.BuildFQName(system, "stream", "last_seq"),
 			"Last sequence from a stream",
 			streamLabels,
-			nil,
 		),
 		// jetstream_stream_consumer_count
-		streamConsumerCount: prometheus.NewDesc(
+		streamConsumerCount: descs.gauge(
 			prometheus.BuildFQName(system, "stream", "consumer_count"),
 			"Total number of consumers from a stream",
 			streamLabels,
-			nil,
+		),
+		// jetstream_stream_num_deleted
+		streamNumDeleted: descs.gauge(
+			prometheus.BuildFQName(system, "stream", "num_deleted"),
+			"Number of deleted messages from a stream",
+			streamLabels,
+		),
+		// jetstream_stream_lost_messages
+		streamLostMessages: descs.gauge(
+			prometheus.BuildFQName(system, "stream", "lost_messages"),
+			"Number of messages lost from a stream",
+			streamLabels,
+		),
+		// jetstream_account_streams_by_replicas
+		accountStreamsByReplicas: descs.gauge(
+			prometheus.BuildFQName(system, "account", "streams_by_replicas"),
+			"Number of streams of an account with a given number of replicas",
+			accountReplicasLabels,
+		),
+		// jetstream_stream_max_consumer_lag
+		streamMaxLag: descs.gauge(
+			prometheus.BuildFQName(system, "stream", "max_consumer_lag"),
+			"Largest number of stream messages not acknowledged by a consumer of the stream",
+			streamLabels,
+		),
+		// jetstream_stream_max_consumers
+		streamMaxConsumers: descs.gauge(
+			prometheus.BuildFQName(system, "stream", "max_consumers"),
+			"Maximum number of consumers of a stream",
+			streamLabels,
+		),
+		// jetstream_stream_avg_msg_bytes
+		streamAvgMsgBytes: descs.gauge(
+			prometheus.BuildFQName(system, "stream", "avg_msg_bytes"),
+			"Average size of the messages of a stream in bytes",
+			streamLabels,
+		),
+		// jetstream_stream_oldest_msg_timestamp_seconds
+		streamOldestMsg: descs.gauge(
+			prometheus.BuildFQName(system, "stream", "oldest_msg_timestamp_seconds"),
+			"Time at which the oldest message of a stream was stored",
+			streamLabels,
 		),
 		// jetstream_consumer_delivered_consumer_seq
-		consumerDeliveredConsumerSeq: prometheus.NewDesc(
+		consumerDeliveredConsumerSeq: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "delivered_consumer_seq"),
 			"Latest sequence number of a stream consumer",
 			consumerLabels,
-			nil,
 		),
 		// jetstream_consumer_delivered_stream_seq
-		consumerDeliveredStreamSeq: prometheus.NewDesc(
+		consumerDeliveredStreamSeq: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "delivered_stream_seq"),
 			"Latest sequence number of a stream",
 			consumerLabels,
-			nil,
 		),
 		// jetstream_consumer_num_ack_pending
-		consumerNumAckPending: prometheus.NewDesc(
+		consumerNumAckPending: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "num_ack_pending"),
 			"Number of pending acks from a consumer",
 			consumerLabels,
-			nil,
 		),
 		// jetstream_consumer_num_redelivered
-		consumerNumRedelivered: prometheus.NewDesc(
+		consumerNumRedelivered: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "num_redelivered"),
 			"Number of redelivered messages from a consumer",
 			consumerLabels,
-			nil,
 		),
 		// jetstream_consumer_num_waiting
-		consumerNumWaiting: prometheus.NewDesc(
+		consumerNumWaiting: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "num_waiting"),
 			"Number of inflight fetch requests from a pull consumer",
 			consumerLabels,
-			nil,
 		),
 		// jetstream_consumer_num_pending
-		consumerNumPending: prometheus.NewDesc(
+		consumerNumPending: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "num_pending"),
 			"Number of pending messages from a consumer",
 			consumerLabels,
-			nil,
 		),
-		consumerAckFloorStreamSeq: prometheus.NewDesc(
+		consumerAckFloorStreamSeq: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "ack_floor_stream_seq"),
 			"Number of ack floor stream seq from a consumer",
 			consumerLabels,
-			nil,
 		),
-		consumerAckFloorConsumerSeq: prometheus.NewDesc(
+		consumerAckFloorConsumerSeq: descs.gauge(
 			prometheus.BuildFQName(system, "consumer", "ack_floor_consumer_seq"),
 			"Number of ack floor consumer seq from a consumer",
 			consumerLabels,
-			nil,
+		),
+		// jetstream_consumer_idle_seconds
+		consumerIdle: descs.gauge(
+			prometheus.BuildFQName(system, "consumer", "idle_seconds"),
+			"Time since the consumer was last active",
+			consumerLabels,
+		),
+		// jetstream_consumer_inactive_threshold_seconds
+		consumerInactiveThreshold: descs.gauge(
+			prometheus.BuildFQName(system, "consumer", "inactive_threshold_seconds"),
+			"Idle time after which the consumer is removed",
+			consumerLabels,
+		),
+		// jetstream_consumer_is_push
+		consumerIsPush: descs.gauge(
+			prometheus.BuildFQName(system, "consumer", "is_push"),
+			"Whether the consumer delivers its messages to a subject",
+			consumerLabels,
 		),
 	}
+	// jetstream_stream_subject
+	if nc.subjects {
+		nc.streamSubject = descs.gauge(
+			prometheus.BuildFQName(system, "stream", "subject"),
+			"Subject captured by a stream",
+			streamSubjectLabels,
+		)
+	}
 
 	// Use the endpoint
 	nc.servers = make([]*CollectedServer, len(servers))
@@ -236,37 +387,60 @@ func newJszCollector(system, endpoint string, servers []*CollectedServer) promet
 	return nc
 }
 
-// Describe shares the info description from a prometheus metric.
-func (nc *jszCollector) Describe(ch chan<- *prometheus.Desc) {
-	// Server state
-	ch <- nc.disabled
-	ch <- nc.streams
-	ch <- nc.consumers
-	ch <- nc.messages
-	ch <- nc.bytes
-	ch <- nc.maxMemory
-	ch <- nc.maxStorage
-
-	// Stream state
-	ch <- nc.streamMessages
-	ch <- nc.streamBytes
-	ch <- nc.streamFirstSeq
-	ch <- nc.streamLastSeq
-	ch <- nc.streamConsumerCount
-
-	// Consumer state
-	ch <- nc.consumerDeliveredConsumerSeq
-	ch <- nc.consumerDeliveredStreamSeq
-	ch <- nc.consumerNumAckPending
-	ch <- nc.consumerNumRedelivered
-	ch <- nc.consumerNumWaiting
-	ch <- nc.consumerNumPending
+// collectStreamsByReplicas reports the number of streams of an account for
+// each number of replicas.  The replicas are those of the stream config when
+// it is reported, else the peers of the stream cluster.
+func (nc *jszCollector) collectStreamsByReplicas(streams []nats.StreamDetail, ch chan<- prometheus.Metric,
+	accountLabelValues ...string) {
+	if len(streams) == 0 {
+		return
+	}
+	buckets := make(map[int]float64)
+	for _, stream := range streams {
+		replicas := 1
+		switch {
+		case stream.Config != nil && stream.Config.Replicas > 0:
+			replicas = stream.Config.Replicas
+		case stream.Cluster != nil:
+			replicas += len(stream.Cluster.Replicas)
+		}
+		buckets[replicas]++
+	}
+	counts := make([]int, 0, len(buckets))
+	for replicas := range buckets {
+		counts = append(counts, replicas)
+	}
+	sort.Ints(counts)
+	for _, replicas := range counts {
+		labelValues := append(append([]string{}, accountLabelValues...), strconv.Itoa(replicas))
+		ch <- prometheus.MustNewConstMetric(nc.accountStreamsByReplicas, prometheus.GaugeValue,
+			buckets[replicas], labelValues...)
+	}
+}
+
+// metaLeaderSince returns when the meta leader of a cluster was first seen
+// leading, as of now according to the server.
+func (nc *jszCollector) metaLeaderSince(cluster, leader string, now time.Time) time.Time {
+	if now.IsZero() {
+		now = time.Now()
+	}
+	nc.Lock()
+	defer nc.Unlock()
+	if nc.metaLeaders == nil {
+		nc.metaLeaders = make(map[string]metaLeader)
+	}
+	ml, ok := nc.metaLeaders[cluster]
+	if !ok || ml.name != leader {
+		ml = metaLeader{name: leader, since: now}
+		nc.metaLeaders[cluster] = ml
+	}
+	return ml.since
 }
 
 // Collect gathers the server jsz metrics.
 func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, server := range nc.servers {
-		var resp nats.JSInfo
+		var resp jszResponse
 		var suffix string
 
 		switch strings.ToLower(nc.endpoint) {
@@ -275,7 +449,8 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 		case "consumer", "consumers", "all":
 			suffix = "/jsz?consumers=true&config=true"
 		case "stream", "streams":
-			suffix = "/jsz?streams=true"
+			// The config holds the max consumers, and the subjects.
+			suffix = "/jsz?streams=true&config=true"
 		default:
 			suffix = "/jsz"
 		}
@@ -283,6 +458,10 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 			Debugf("ignoring server %s: %v", server.ID, err)
 			continue
 		}
+		if nc.domain != "" && resp.Config.Domain != nc.domain {
+			Debugf("ignoring server %s: not in JetStream domain %q", server.ID, nc.domain)
+			continue
+		}
 		var varz nats.Varz
 		if err := getMetricURL(nc.httpClient, server.URL+"/varz", &varz); err != nil {
 			Debugf("ignoring server %s: %v", server.ID, err)
@@ -290,7 +469,7 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 		var serverID, serverName, clusterName, jsDomain, clusterLeader string
 		var streamName, streamLeader string
-		var consumerName, consumerDesc, consumerLeader string
+		var consumerName, consumerDesc, consumerLeader, consumerType string
 		var isMetaLeader, isStreamLeader, isConsumerLeader string
 		var accountName string
 		var accountID string
@@ -322,14 +501,37 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- serverMetric(nc.disabled, isJetStreamDisabled)
 		ch <- serverMetric(nc.maxMemory, float64(resp.Config.MaxMemory))
 		ch <- serverMetric(nc.maxStorage, float64(resp.Config.MaxStore))
+		ch <- serverMetric(nc.reservedMemory, float64(resp.ReservedMemory))
+		ch <- serverMetric(nc.reservedStorage, float64(resp.ReservedStore))
 		ch <- serverMetric(nc.streams, float64(resp.Streams))
 		ch <- serverMetric(nc.consumers, float64(resp.Consumers))
 		ch <- serverMetric(nc.messages, float64(resp.Messages))
 		ch <- serverMetric(nc.bytes, float64(resp.Bytes))
+		if resp.API != nil {
+			ch <- serverMetric(nc.apiInflight, float64(resp.API.Inflight))
+		}
+		if resp.Meta != nil && resp.Meta.Leader != "" {
+			since := nc.metaLeaderSince(resp.Meta.Name, resp.Meta.Leader, resp.Now)
+			ch <- serverMetric(nc.metaLeaderChange, float64(since.UnixNano())/1e9)
+		}
+		if resp.Meta != nil && resp.Meta.Size > 0 {
+			// The replicas are the peers of the server, which is online.
+			currentSize := 1
+			for _, peer := range resp.Meta.Replicas {
+				if !peer.Offline {
+					currentSize++
+				}
+			}
+			ch <- serverMetric(nc.clusterExpectedSize, float64(resp.Meta.Size))
+			ch <- serverMetric(nc.clusterCurrentSize, float64(currentSize))
+		}
 
 		for _, account := range resp.AccountDetails {
 			accountName = account.Name
 			accountID = account.Id
+			nc.collectStreamsByReplicas(account.Streams, ch,
+				serverID, serverName, clusterName, jsDomain, clusterLeader, isMetaLeader,
+				accountName, accountID)
 			for _, stream := range account.Streams {
 				streamName = stream.Name
 				if stream.Cluster != nil {
@@ -354,6 +556,52 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 				ch <- streamMetric(nc.streamFirstSeq, float64(stream.State.FirstSeq))
 				ch <- streamMetric(nc.streamLastSeq, float64(stream.State.LastSeq))
 				ch <- streamMetric(nc.streamConsumerCount, float64(stream.State.Consumers))
+				ch <- streamMetric(nc.streamNumDeleted, float64(stream.State.NumDeleted))
+
+				// The average of an empty stream is reported as zero.
+				var avgMsgBytes float64
+				if stream.State.Msgs > 0 {
+					avgMsgBytes = float64(stream.State.Bytes) / float64(stream.State.Msgs)
+				}
+				ch <- streamMetric(nc.streamAvgMsgBytes, avgMsgBytes)
+
+				// Empty streams have no oldest message.
+				if stream.State.Msgs > 0 && !stream.State.FirstTime.IsZero() {
+					ch <- streamMetric(nc.streamOldestMsg, float64(stream.State.FirstTime.UnixNano())/1e9)
+				}
+
+				// The lost block is only reported when messages have been lost.
+				var lostMessages float64
+				if stream.State.Lost != nil {
+					lostMessages = float64(len(stream.State.Lost.Msgs))
+				}
+				ch <- streamMetric(nc.streamLostMessages, lostMessages)
+
+				// The maximum is only reported for the streams limiting their consumers.
+				if stream.Config != nil && stream.Config.MaxConsumers > 0 {
+					ch <- streamMetric(nc.streamMaxConsumers, float64(stream.Config.MaxConsumers))
+				}
+
+				if nc.subjects && stream.Config != nil {
+					for _, subject := range stream.Config.Subjects {
+						ch <- prometheus.MustNewConstMetric(nc.streamSubject, prometheus.GaugeValue, 1,
+							serverID, serverName, clusterName, jsDomain, clusterLeader, isMetaLeader,
+							accountName, accountID, streamName, streamLeader, isStreamLeader,
+							subject)
+					}
+				}
+
+				// The lag of a consumer is the number of messages after its ack floor.
+				if len(stream.Consumer) > 0 {
+					var maxLag uint64
+					for _, consumer := range stream.Consumer {
+						if lastSeq := stream.State.LastSeq; lastSeq > consumer.AckFloor.Stream &&
+							lastSeq-consumer.AckFloor.Stream > maxLag {
+							maxLag = lastSeq - consumer.AckFloor.Stream
+						}
+					}
+					ch <- streamMetric(nc.streamMaxLag, float64(maxLag))
+				}
 
 				// Now with the consumers.
 				for _, consumer := range stream.Consumer {
@@ -361,6 +609,16 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 					if consumer.Config != nil {
 						consumerDesc = consumer.Config.Description
 					}
+					// Push consumers deliver to a subject, the type is unknown
+					// without the consumer config.
+					consumerType = ""
+					if consumer.Config != nil {
+						if consumer.Config.DeliverSubject != "" {
+							consumerType = "push"
+						} else {
+							consumerType = "pull"
+						}
+					}
 					if consumer.Cluster != nil {
 						consumerLeader = consumer.Cluster.Leader
 						if consumerLeader == serverName {
@@ -378,7 +636,7 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 							// Stream Labels
 							accountName, accountID, streamName, streamLeader, isStreamLeader,
 							// Consumer Labels
-							consumerName, consumerLeader, isConsumerLeader, consumerDesc,
+							consumerName, consumerLeader, isConsumerLeader, consumerDesc, consumerType,
 						)
 					}
 					ch <- consumerMetric(nc.consumerDeliveredConsumerSeq, float64(consumer.Delivered.Consumer))
@@ -389,6 +647,17 @@ func (nc *jszCollector) Collect(ch chan<- prometheus.Metric) {
 					ch <- consumerMetric(nc.consumerNumPending, float64(consumer.NumPending))
 					ch <- consumerMetric(nc.consumerAckFloorStreamSeq, float64(consumer.AckFloor.Stream))
 					ch <- consumerMetric(nc.consumerAckFloorConsumerSeq, float64(consumer.AckFloor.Consumer))
+					// The idle time is unknown without the time of the server.
+					if consumer.Delivered.Last != nil && !resp.Now.IsZero() {
+						idle := resp.Now.Sub(*consumer.Delivered.Last)
+						ch <- consumerMetric(nc.consumerIdle, idle.Seconds())
+					}
+					if consumer.Config != nil && consumer.Config.InactiveThreshold > 0 {
+						ch <- consumerMetric(nc.consumerInactiveThreshold, consumer.Config.InactiveThreshold.Seconds())
+					}
+					if consumer.Config != nil {
+						ch <- consumerMetric(nc.consumerIsPush, boolToFloat(consumer.Config.DeliverSubject != ""))
+					}
 				}
 			}
 		}
@@ -31,22 +31,30 @@ func isLeafzEndpoint(system, endpoint string) bool {
 // leafzCollector is responsible to gather metrics on leaf nodes.
 type leafzCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient     *http.Client
 	servers        []*CollectedServer
 	leafNodesTotal *prometheus.Desc
+	accountNodes   *prometheus.Desc
 	leafMetrics    *leafMetrics
 }
 
 // newLeafzCollector creates a new instance of a leafzCollector.
-func newLeafzCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
-	nc := &leafzCollector{httpClient: http.DefaultClient}
-	nc.leafNodesTotal = prometheus.NewDesc(
+func newLeafzCollector(system, endpoint string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
+	descs := &metricDescs{}
+	nc := &leafzCollector{metricDescs: descs, httpClient: &http.Client{Transport: transport}}
+	nc.leafNodesTotal = descs.gauge(
 		prometheus.BuildFQName(system, endpoint, "conn_nodes_total"),
 		"nodes_total",
 		[]string{"server_id"},
-		nil)
-	nc.leafMetrics = newLeafMetrics(system, endpoint)
+	)
+	nc.accountNodes = descs.gauge(
+		prometheus.BuildFQName(system, endpoint, "account_nodes"),
+		"number of leaf node connections of an account",
+		[]string{"server_id", "account"},
+	)
+	nc.leafMetrics = newLeafMetrics(descs, system, endpoint)
 	nc.servers = make([]*CollectedServer, len(servers))
 	for i, s := range servers {
 		nc.servers[i] = &CollectedServer{
@@ -57,13 +65,6 @@ func newLeafzCollector(system, endpoint string, servers []*CollectedServer) prom
 	return nc
 }
 
-// Describe destribes the list of prometheus descriptors available
-// to be scraped.
-func (nc *leafzCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nc.leafNodesTotal
-	nc.leafMetrics.Describe(ch)
-}
-
 // Collect gathers the server leafz metrics.
 func (nc *leafzCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, server := range nc.servers {
@@ -72,11 +73,21 @@ func (nc *leafzCollector) Collect(ch chan<- prometheus.Metric) {
 			Debugf("ignoring server %s: %v", server.ID, err)
 			continue
 		}
+		var accounts []string
+		accountNodes := make(map[string]float64)
 		for _, lf := range resp.Leafs {
 			nc.leafMetrics.Collect(server, lf, ch)
+			if _, ok := accountNodes[lf.Account]; !ok {
+				accounts = append(accounts, lf.Account)
+			}
+			accountNodes[lf.Account]++
 		}
 		ch <- prometheus.MustNewConstMetric(nc.leafNodesTotal, prometheus.GaugeValue,
 			float64(resp.LeafNodes), server.ID)
+		for _, account := range accounts {
+			ch <- prometheus.MustNewConstMetric(nc.accountNodes, prometheus.GaugeValue,
+				accountNodes[account], server.ID, account)
+		}
 	}
 }
 
@@ -94,66 +105,53 @@ type leafMetrics struct {
 }
 
 // newLeafMetrics initializes a new instance of leafMetrics.
-func newLeafMetrics(system, endpoint string) *leafMetrics {
+func newLeafMetrics(descs *metricDescs, system, endpoint string) *leafMetrics {
 	leaf := &leafMetrics{
-		info: prometheus.NewDesc(
+		info: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "info"),
 			"info",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connRtt: prometheus.NewDesc(
+		),
+		connRtt: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_rtt"),
 			"rtt",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connInMsgs: prometheus.NewDesc(
+		),
+		connInMsgs: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_in_msgs"),
 			"in_msgs",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connOutMsgs: prometheus.NewDesc(
+		),
+		connOutMsgs: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_out_msgs"),
 			"out_msgs",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connInBytes: prometheus.NewDesc(
+		),
+		connInBytes: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_in_bytes"),
 			"in_bytes",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connOutBytes: prometheus.NewDesc(
+		),
+		connOutBytes: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_out_bytes"),
 			"out_bytes",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connSubscriptionsTotal: prometheus.NewDesc(
+		),
+		connSubscriptionsTotal: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_subscriptions_total"),
 			"subscriptions_total",
 			[]string{"server_id", "account", "ip", "port"},
-			nil),
-		connSubscriptions: prometheus.NewDesc(
+		),
+		connSubscriptions: descs.gauge(
 			prometheus.BuildFQName(system, endpoint, "conn_subscriptions"),
 			"subscriptions",
 			[]string{"server_id", "account", "ip", "port", "subscription"},
-			nil),
+		),
 	}
 
 	return leaf
 }
 
-// Describe destribes the list of prometheus descriptors available
-// to be scraped.
-func (lm *leafMetrics) Describe(ch chan<- *prometheus.Desc) {
-	ch <- lm.info
-	ch <- lm.connRtt
-	ch <- lm.connInMsgs
-	ch <- lm.connOutMsgs
-	ch <- lm.connInBytes
-	ch <- lm.connOutBytes
-	ch <- lm.connSubscriptionsTotal
-	ch <- lm.connSubscriptions
-}
-
 // Collect collects all the metrics about the a leafnode connection.
 func (lm *leafMetrics) Collect(server *CollectedServer, lf *Leaf, ch chan<- prometheus.Metric) {
 
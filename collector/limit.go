@@ -0,0 +1,50 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"io"
+	"sync"
+)
+
+// acquireEndpointSlot waits until a request to a server can run, and
+// returns the function to call once it has completed.
+func (t *Transport) acquireEndpointSlot(host string) func() {
+	if t.limit <= 0 {
+		return func() {}
+	}
+	t.mu.Lock()
+	slots, ok := t.slots[host]
+	if !ok {
+		slots = make(chan struct{}, t.limit)
+		t.slots[host] = slots
+	}
+	t.mu.Unlock()
+
+	slots <- struct{}{}
+	var once sync.Once
+	return func() { once.Do(func() { <-slots }) }
+}
+
+// releasingBody is a response body releasing the slot of its request once
+// closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ParseSHA256Fingerprint parses a hex encoded SHA-256 certificate
+// fingerprint, its bytes optionally separated by colons.
+func ParseSHA256Fingerprint(fingerprint string) ([]byte, error) {
+	sum, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("invalid SHA-256 fingerprint %q", fingerprint)
+	}
+	return sum, nil
+}
+
+// PinServerCertificate makes the requests of the transport to a server over
+// HTTPS trust the certificate with the given SHA-256 fingerprint, instead
+// of verifying it against the CAs.
+func (t *Transport) PinServerCertificate(serverURL, fingerprint string) error {
+	sum, err := ParseSHA256Fingerprint(fingerprint)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pinned[u.Host] = newPinnedTransport(t.base, sum)
+	return nil
+}
+
+// PinServerCertificates pins the certificates of the servers with a
+// fingerprint.
+func (t *Transport) PinServerCertificates(servers []*CollectedServer) {
+	for _, s := range servers {
+		if s.TLSPinnedSHA256 == "" {
+			continue
+		}
+		if err := t.PinServerCertificate(s.URL, s.TLSPinnedSHA256); err != nil {
+			Errorf("not pinning the certificate of server %s: %v", s.ID, err)
+		}
+	}
+}
+
+// newPinnedTransport returns a copy of a transport only trusting the
+// servers whose leaf certificate has the given SHA-256 fingerprint.
+func newPinnedTransport(base *http.Transport, sum []byte) *http.Transport {
+	t := base.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	// The chain is not verified, the fingerprint of the leaf is.
+	t.TLSClientConfig.InsecureSkipVerify = true
+	t.TLSClientConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("no certificate presented")
+		}
+		if leaf := sha256.Sum256(rawCerts[0]); !bytes.Equal(leaf[:], sum) {
+			return fmt.Errorf("certificate fingerprint %x does not match the pinned one", leaf)
+		}
+		return nil
+	}
+	return t
+}
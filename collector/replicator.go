@@ -23,6 +23,7 @@ import (
 
 type replicatorCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient *http.Client
 	servers    []*CollectedServer
@@ -80,110 +81,95 @@ func isReplicatorEndpoint(system, endpoint string) bool {
 	return system == ReplicatorSystem && endpoint == "varz"
 }
 
-func newReplicatorCollector(system string, servers []*CollectedServer) prometheus.Collector {
+func newReplicatorCollector(system string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
+	descs := &metricDescs{}
 	nc := &replicatorCollector{
-		httpClient: http.DefaultClient,
-		startTime: prometheus.NewDesc(
+		metricDescs: descs,
+		httpClient:  &http.Client{Transport: transport},
+		startTime: descs.counter(
 			prometheus.BuildFQName(system, "server", "start_time"),
 			"Start Time",
 			[]string{"server_id"},
-			nil,
 		),
-		currentTime: prometheus.NewDesc(
+		currentTime: descs.counter(
 			prometheus.BuildFQName(system, "server", "current_time"),
 			"Current Time",
 			[]string{"server_id"},
-			nil,
 		),
-		requestCount: prometheus.NewDesc(
+		requestCount: descs.counter(
 			prometheus.BuildFQName(system, "server", "request_count"),
 			"Request Count",
 			[]string{"server_id"},
-			nil,
 		),
-		info: prometheus.NewDesc(
+		info: descs.gauge(
 			prometheus.BuildFQName(system, "server", "info"),
 			"Info",
 			[]string{"server_id", "uptime"},
-			nil,
 		),
-		connected: prometheus.NewDesc(
+		connected: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "connected"),
 			"Connected",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		connects: prometheus.NewDesc(
+		connects: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "connects"),
 			"Connects",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		disconnects: prometheus.NewDesc(
+		disconnects: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "disconnects"),
 			"Disonnects",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		bytesIn: prometheus.NewDesc(
+		bytesIn: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "bytes_in"),
 			"Bytes In",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		bytesOut: prometheus.NewDesc(
+		bytesOut: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "bytes_out"),
 			"Bytes Out",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		messagesIn: prometheus.NewDesc(
+		messagesIn: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "messages_in"),
 			"Messages In",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		messagesOut: prometheus.NewDesc(
+		messagesOut: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "messages_out"),
 			"Messages Out",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		count: prometheus.NewDesc(
+		count: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "request_count"),
 			"Connector Request Count",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		movingAverage: prometheus.NewDesc(
+		movingAverage: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "moving_average"),
 			"Connector Moving Average",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		quintile50: prometheus.NewDesc(
+		quintile50: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "quintile_50"),
 			"Connector 50th Quintile",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		quintile75: prometheus.NewDesc(
+		quintile75: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "quintile_75"),
 			"Connector 75th Quintile",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		quintile90: prometheus.NewDesc(
+		quintile90: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "quintile_90"),
 			"Connector 90th Quintile",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
-		quintile95: prometheus.NewDesc(
+		quintile95: descs.gauge(
 			prometheus.BuildFQName(system, "connector", "quintile_95"),
 			"Connector 95th Quintile",
 			[]string{"server_id", "connector_id", "name"},
-			nil,
 		),
 	}
 
@@ -198,26 +184,6 @@ func newReplicatorCollector(system string, servers []*CollectedServer) prometheu
 	return nc
 }
 
-func (nc *replicatorCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nc.startTime
-	ch <- nc.currentTime
-	ch <- nc.requestCount
-	ch <- nc.info
-	ch <- nc.connected
-	ch <- nc.connects
-	ch <- nc.disconnects
-	ch <- nc.bytesIn
-	ch <- nc.bytesOut
-	ch <- nc.messagesIn
-	ch <- nc.messagesOut
-	ch <- nc.count
-	ch <- nc.movingAverage
-	ch <- nc.quintile50
-	ch <- nc.quintile75
-	ch <- nc.quintile90
-	ch <- nc.quintile95
-}
-
 // Collect gathers the streaming server serverz metrics.
 func (nc *replicatorCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, server := range nc.servers {
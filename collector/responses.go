@@ -0,0 +1,77 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sync"
+)
+
+// MaxRecordedResponseSize is the maximum number of bytes kept from each
+// recorded response.
+const MaxRecordedResponseSize = 1024 * 1024
+
+var (
+	responsesMu      sync.Mutex
+	recordResponses  bool
+	lastResponses    map[string][]byte
+	urlCredentialsRe = regexp.MustCompile(`://[^/\s"@]+@`)
+)
+
+// RecordLastResponses enables or disables keeping the last response body
+// received from each monitoring endpoint.  Disabling it drops the recorded
+// responses.
+func RecordLastResponses(enabled bool) {
+	responsesMu.Lock()
+	defer responsesMu.Unlock()
+	recordResponses = enabled
+	lastResponses = nil
+}
+
+// LastResponse returns the last response body received from the
+// monitoring URL, ignoring its query, with the credentials of the URLs it
+// contains redacted.
+func LastResponse(monitorURL string) ([]byte, bool) {
+	responsesMu.Lock()
+	defer responsesMu.Unlock()
+	body, ok := lastResponses[responseKey(monitorURL)]
+	return body, ok
+}
+
+func recordResponse(monitorURL string, body []byte) {
+	responsesMu.Lock()
+	defer responsesMu.Unlock()
+	if !recordResponses {
+		return
+	}
+	if len(body) > MaxRecordedResponseSize {
+		body = body[:MaxRecordedResponseSize]
+	}
+	if lastResponses == nil {
+		lastResponses = make(map[string][]byte)
+	}
+	lastResponses[responseKey(monitorURL)] = urlCredentialsRe.ReplaceAll(body, []byte("://[REDACTED]@"))
+}
+
+// responseKey identifies a monitoring endpoint by its URL without
+// credentials nor query.
+func responseKey(monitorURL string) string {
+	u, err := url.Parse(monitorURL)
+	if err != nil {
+		return monitorURL
+	}
+	return u.Scheme + "://" + u.Host + path.Clean("/"+u.Path)
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// isRoutezEndpoint returns wether an endpoint is a routez or not.
+func isRoutezEndpoint(system, endpoint string) bool {
+	return system == CoreSystem && endpoint == "routez"
+}
+
+// routezCollector adds metrics on the completeness of the route mesh to
+// the generic routez metrics.
+type routezCollector struct {
+	*NATSCollector
+	metricDescs
+
+	expectedRoutes *prometheus.Desc
+	activeRoutes   *prometheus.Desc
+	pendingBytes   *prometheus.Desc
+}
+
+// newRoutezCollector creates a new instance of a routezCollector.
+func newRoutezCollector(system, endpoint string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
+	nc := &routezCollector{
+		NATSCollector: newNatsCollector(system, endpoint, servers, transport).(*NATSCollector),
+	}
+	nc.expectedRoutes = nc.metricDescs.gauge(
+		prometheus.BuildFQName(system, endpoint, "expected_routes"),
+		"Number of routes expected in a full mesh of the known cluster members",
+		[]string{"server_id"},
+	)
+	nc.activeRoutes = nc.metricDescs.gauge(
+		prometheus.BuildFQName(system, endpoint, "active_routes"),
+		"Number of cluster members this server has a route to",
+		[]string{"server_id"},
+	)
+	nc.pendingBytes = nc.metricDescs.gauge(
+		prometheus.BuildFQName(system, endpoint, "route_pending_bytes"),
+		"Number of bytes pending to be sent on a route",
+		[]string{"server_id", "rid", "remote_id"},
+	)
+	return nc
+}
+
+// Describe destribes the list of prometheus descriptors available
+// to be scraped.
+func (nc *routezCollector) Describe(ch chan<- *prometheus.Desc) {
+	DescribeMetricDescs(nc.MetricDescs(), ch)
+}
+
+// MetricDescs implements MetricDescriber.
+func (nc *routezCollector) MetricDescs() []*MetricDesc {
+	return append(nc.NATSCollector.MetricDescs(), nc.metricDescs.MetricDescs()...)
+}
+
+// Collect gathers the generic routez metrics, along with the expected and
+// active routes of each server.  The cluster members are the servers
+// polled along with the remote servers of their routes, so a missing route
+// can only be detected when several members of the cluster are polled.
+func (nc *routezCollector) Collect(ch chan<- prometheus.Metric) {
+	nc.Lock()
+	defer nc.Unlock()
+
+	resps := nc.makeRequests()
+	if len(resps) == 0 {
+		return
+	}
+	for key, stat := range nc.Stats {
+		nc.collectStatsFromRequests(key, stat, resps, ch)
+	}
+
+	members := make(map[string]struct{})
+	remotes := make(map[string]map[string]struct{})
+	for id, response := range resps {
+		self, ok := response["server_id"].(string)
+		if !ok {
+			continue
+		}
+		members[self] = struct{}{}
+		remotes[id] = make(map[string]struct{})
+		routes, _ := response["routes"].([]interface{})
+		for _, r := range routes {
+			route, _ := r.(map[string]interface{})
+			remote, ok := route["remote_id"].(string)
+			if !ok {
+				continue
+			}
+			if remote != self {
+				members[remote] = struct{}{}
+				remotes[id][remote] = struct{}{}
+			}
+			if pending, ok := route["pending_size"].(float64); ok {
+				ch <- prometheus.MustNewConstMetric(nc.pendingBytes, prometheus.GaugeValue, pending,
+					id, fmt.Sprint(route["rid"]), remote)
+			}
+		}
+	}
+
+	for id, r := range remotes {
+		ch <- prometheus.MustNewConstMetric(nc.expectedRoutes, prometheus.GaugeValue,
+			float64(len(members)-1), id)
+		ch <- prometheus.MustNewConstMetric(nc.activeRoutes, prometheus.GaugeValue,
+			float64(len(r)), id)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ExporterSystem is the namespace of the metrics about the exporter itself.
+const ExporterSystem = "nats_exporter"
+
+const fetchRTTEndpoint = "fetch_rtt"
+
+func isFetchRTTEndpoint(system, endpoint string) bool {
+	return system == ExporterSystem && endpoint == fetchRTTEndpoint
+}
+
+// httpGet gets a monitoring URL.
+func httpGet(httpClient *http.Client, monitorURL string) (*http.Response, error) {
+	return httpClient.Get(monitorURL)
+}
+
+func (t *Transport) recordFetchRTT(monitorURL string, rtt time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rtts[responseKey(monitorURL)] = rtt
+}
+
+// fetchRTTs returns a copy of the round trip times recorded.
+func (t *Transport) fetchRTTs() map[string]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rtts := make(map[string]time.Duration, len(t.rtts))
+	for k, v := range t.rtts {
+		rtts[k] = v
+	}
+	return rtts
+}
+
+// fetchRTTCollector reports the last round trip time of the requests of
+// its transport to each monitoring endpoint of the servers.
+type fetchRTTCollector struct {
+	*metricDescs
+	servers   []*CollectedServer
+	transport *Transport
+	rtt       *prometheus.Desc
+}
+
+func newFetchRTTCollector(servers []*CollectedServer, transport *Transport) prometheus.Collector {
+	descs := &metricDescs{}
+	return &fetchRTTCollector{
+		metricDescs: descs,
+		servers:     servers,
+		transport:   transport,
+		rtt: descs.gauge(
+			prometheus.BuildFQName(ExporterSystem, "fetch", "rtt_seconds"),
+			"Time in seconds taken by the last request to the monitoring endpoint to get a response",
+			[]string{"server_id", "endpoint"},
+		),
+	}
+}
+
+// Collect gathers the round trip times recorded for the servers.
+func (nc *fetchRTTCollector) Collect(ch chan<- prometheus.Metric) {
+	rtts := nc.transport.fetchRTTs()
+	for _, server := range nc.servers {
+		for key, rtt := range rtts {
+			if endpoint, ok := serverEndpoint(server, key); ok {
+				ch <- prometheus.MustNewConstMetric(nc.rtt, prometheus.GaugeValue, rtt.Seconds(),
+					server.ID, endpoint)
+			}
+		}
+	}
+}
+
+// serverEndpoint returns the endpoint of a server the response key of a
+// monitoring URL is for, if any.
+func serverEndpoint(server *CollectedServer, key string) (string, bool) {
+	base := strings.TrimSuffix(responseKey(server.URL), "/") + "/"
+	if !strings.HasPrefix(key, base) {
+		return "", false
+	}
+	return strings.TrimPrefix(key, base), true
+}
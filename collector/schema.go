@@ -0,0 +1,117 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// endpointSchemas are the types of the responses of the endpoints whose
+// metrics are defined from the fields of their responses.
+var endpointSchemas = map[string]reflect.Type{
+	"varz":   reflect.TypeOf(server.Varz{}),
+	"subsz":  reflect.TypeOf(server.Subsz{}),
+	"routez": reflect.TypeOf(server.Routez{}),
+}
+
+// endpointExtraFields are the numeric fields only some servers report,
+// which the response types do not have.
+var endpointExtraFields = map[string][]string{
+	"varz": {"open_fds", "max_fds"},
+}
+
+// schemaMapKeys are the keys of the numeric map fields of the responses.
+var schemaMapKeys = map[string][]string{
+	"http_req_stats": {
+		server.RootPath, server.VarzPath, server.ConnzPath, server.RoutezPath,
+		server.GatewayzPath, server.LeafzPath, server.SubszPath, server.StackszPath,
+		server.AccountzPath, server.AccountStatzPath, server.JszPath, server.HealthzPath,
+		server.IPQueuesPath,
+	},
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaResponse returns a response of the endpoint with all the fields
+// it may have, numbers being zero and strings empty, from which its
+// metrics are defined.
+func schemaResponse(endpoint string) (map[string]interface{}, bool) {
+	t, ok := endpointSchemas[endpoint]
+	if !ok {
+		return nil, false
+	}
+	response := make(map[string]interface{})
+	addSchemaFields(response, t)
+	for _, name := range endpointExtraFields[endpoint] {
+		response[name] = float64(0)
+	}
+	return response, true
+}
+
+func addSchemaFields(response map[string]interface{}, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// The fields of embedded structs are those of the response.
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addSchemaFields(response, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if ft == timeType || ft.Implements(marshalerType) || reflect.PtrTo(ft).Implements(marshalerType) {
+			continue
+		}
+
+		switch ft.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			response[name] = float64(0)
+		case reflect.String:
+			response[name] = ""
+		case reflect.Struct:
+			nested := make(map[string]interface{})
+			addSchemaFields(nested, ft)
+			response[name] = nested
+		case reflect.Map:
+			if keys, ok := schemaMapKeys[name]; ok {
+				nested := make(map[string]interface{})
+				for _, k := range keys {
+					nested[k] = float64(0)
+				}
+				response[name] = nested
+			}
+		}
+	}
+}
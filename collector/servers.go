@@ -0,0 +1,64 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"net/url"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const configuredServersEndpoint = "configured_servers"
+
+func isConfiguredServersEndpoint(system, endpoint string) bool {
+	return system == ExporterSystem && endpoint == configuredServersEndpoint
+}
+
+// configuredServersCollector reports the servers polled by the exporter.
+type configuredServersCollector struct {
+	*metricDescs
+	servers []*CollectedServer
+	server  *prometheus.Desc
+}
+
+func newConfiguredServersCollector(servers []*CollectedServer) prometheus.Collector {
+	descs := &metricDescs{}
+	return &configuredServersCollector{
+		metricDescs: descs,
+		servers:     servers,
+		server: descs.gauge(
+			prometheus.BuildFQName(ExporterSystem, "configured", "server"),
+			"Server polled by the exporter, with its monitoring URL",
+			[]string{"server_id", "url"},
+		),
+	}
+}
+
+// Collect reports each of the servers.
+func (nc *configuredServersCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, server := range nc.servers {
+		ch <- prometheus.MustNewConstMetric(nc.server, prometheus.GaugeValue, 1,
+			server.ID, redactURL(server.URL))
+	}
+}
+
+// redactURL removes the credentials from a URL.
+func redactURL(monitorURL string) string {
+	u, err := url.Parse(monitorURL)
+	if err != nil {
+		return urlCredentialsRe.ReplaceAllString(monitorURL, "://")
+	}
+	u.User = nil
+	return u.String()
+}
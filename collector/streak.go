@@ -0,0 +1,86 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const scrapeStreakEndpoint = "scrape_streak"
+
+var (
+	fetchStreaksMu sync.Mutex
+	fetchStreaks   = make(map[string]int)
+)
+
+func isScrapeStreakEndpoint(system, endpoint string) bool {
+	return system == ExporterSystem && endpoint == scrapeStreakEndpoint
+}
+
+// recordFetchResult extends the streak of successful or failed requests to
+// a monitoring URL, or starts a new one when the result changes.
+func recordFetchResult(monitorURL string, err error) {
+	fetchStreaksMu.Lock()
+	defer fetchStreaksMu.Unlock()
+	key := responseKey(monitorURL)
+	streak := fetchStreaks[key]
+	switch {
+	case err == nil && streak > 0:
+		streak++
+	case err == nil:
+		streak = 1
+	case streak < 0:
+		streak--
+	default:
+		streak = -1
+	}
+	fetchStreaks[key] = streak
+}
+
+// scrapeStreakCollector reports the number of consecutive successful, or
+// failed, requests to each monitoring endpoint of the servers.
+type scrapeStreakCollector struct {
+	*metricDescs
+	servers []*CollectedServer
+	streak  *prometheus.Desc
+}
+
+func newScrapeStreakCollector(servers []*CollectedServer) prometheus.Collector {
+	descs := &metricDescs{}
+	return &scrapeStreakCollector{
+		metricDescs: descs,
+		servers:     servers,
+		streak: descs.gauge(
+			prometheus.BuildFQName(ExporterSystem, "scrape", "success_streak"),
+			"Number of consecutive successful requests to the monitoring endpoint, negative for failed requests",
+			[]string{"server_id", "endpoint"},
+		),
+	}
+}
+
+// Collect gathers the streaks recorded for the servers.
+func (nc *scrapeStreakCollector) Collect(ch chan<- prometheus.Metric) {
+	fetchStreaksMu.Lock()
+	defer fetchStreaksMu.Unlock()
+	for _, server := range nc.servers {
+		for key, streak := range fetchStreaks {
+			if endpoint, ok := serverEndpoint(server, key); ok {
+				ch <- prometheus.MustNewConstMetric(nc.streak, prometheus.GaugeValue, float64(streak),
+					server.ID, endpoint)
+			}
+		}
+	}
+}
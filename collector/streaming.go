@@ -31,12 +31,12 @@ const (
 
 // newStreamingCollector collects channelsz and serversz metrics of
 // streaming servers.
-func newStreamingCollector(system, endpoint string, servers []*CollectedServer) prometheus.Collector {
+func newStreamingCollector(system, endpoint string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
 	switch endpoint {
 	case "channelsz":
-		return newChannelsCollector(system, servers)
+		return newChannelsCollector(system, servers, transport)
 	case "serverz":
-		return newServerzCollector(system, servers)
+		return newServerzCollector(system, servers, transport)
 	}
 	return nil
 }
@@ -47,6 +47,7 @@ func isStreamingEndpoint(system, endpoint string) bool {
 
 type serverzCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient *http.Client
 	servers    []*CollectedServer
@@ -65,75 +66,66 @@ type serverzCollector struct {
 	info       *prometheus.Desc
 }
 
-func newServerzCollector(system string, servers []*CollectedServer) prometheus.Collector {
+func newServerzCollector(system string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
+	descs := &metricDescs{}
 	nc := &serverzCollector{
-		httpClient: http.DefaultClient,
-		system:     system,
-		bytesTotal: prometheus.NewDesc(
+		metricDescs: descs,
+		httpClient:  &http.Client{Transport: transport},
+		system:      system,
+		bytesTotal: descs.counter(
 			prometheus.BuildFQName(system, "server", "bytes_total"),
 			"Total of bytes",
 			[]string{"server_id"},
-			nil,
 		),
-		bytesIn: prometheus.NewDesc(
+		bytesIn: descs.counter(
 			prometheus.BuildFQName(system, "server", "bytes_in"),
 			"Incoming bytes",
 			[]string{"server_id"},
-			nil,
 		),
-		bytesOut: prometheus.NewDesc(
+		bytesOut: descs.counter(
 			prometheus.BuildFQName(system, "server", "bytes_out"),
 			"Outgoing bytes",
 			[]string{"server_id"},
-			nil,
 		),
-		msgsTotal: prometheus.NewDesc(
+		msgsTotal: descs.counter(
 			prometheus.BuildFQName(system, "server", "msgs_total"),
 			"Total of messages",
 			[]string{"server_id"},
-			nil,
 		),
-		msgsIn: prometheus.NewDesc(
+		msgsIn: descs.counter(
 			prometheus.BuildFQName(system, "server", "msgs_in"),
 			"Incoming messages",
 			[]string{"server_id"},
-			nil,
 		),
-		msgsOut: prometheus.NewDesc(
+		msgsOut: descs.counter(
 			prometheus.BuildFQName(system, "server", "msgs_out"),
 			"Outgoing messages",
 			[]string{"server_id"},
-			nil,
 		),
-		channels: prometheus.NewDesc(
+		channels: descs.counter(
 			prometheus.BuildFQName(system, "server", "channels"),
 			"Total channels",
 			[]string{"server_id"},
-			nil,
 		),
-		subs: prometheus.NewDesc(
+		subs: descs.counter(
 			prometheus.BuildFQName(system, "server", "subscriptions"),
 			"Total subscriptions",
 			[]string{"server_id"},
-			nil,
 		),
-		clients: prometheus.NewDesc(
+		clients: descs.counter(
 			prometheus.BuildFQName(system, "server", "clients"),
 			"Total clients",
 			[]string{"server_id"},
-			nil,
 		),
-		active: prometheus.NewDesc(
+		active: descs.gauge(
 			prometheus.BuildFQName(system, "server", "active"),
 			"Active server",
 			[]string{"server_id"},
-			nil,
 		),
-		info: prometheus.NewDesc(
+		info: descs.gauge(
 			prometheus.BuildFQName(system, "server", "info"),
 			"Info",
 			[]string{"server_id", "cluster_id", "version", "go_version", "state", "role", "start_time"},
-			nil,
 		),
 	}
 
@@ -148,20 +140,6 @@ func newServerzCollector(system string, servers []*CollectedServer) prometheus.C
 	return nc
 }
 
-func (nc *serverzCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nc.bytesTotal
-	ch <- nc.bytesIn
-	ch <- nc.bytesOut
-	ch <- nc.msgsTotal
-	ch <- nc.msgsIn
-	ch <- nc.msgsOut
-	ch <- nc.channels
-	ch <- nc.subs
-	ch <- nc.clients
-	ch <- nc.active
-	ch <- nc.info
-}
-
 // StreamingServerz represents the metrics from streaming/serverz.
 type StreamingServerz struct {
 	TotalBytes    int    `json:"total_bytes"`
@@ -218,6 +196,7 @@ func (nc *serverzCollector) Collect(ch chan<- prometheus.Metric) {
 
 type channelsCollector struct {
 	sync.Mutex
+	*metricDescs
 
 	httpClient *http.Client
 	servers    []*CollectedServer
@@ -231,49 +210,45 @@ type channelsCollector struct {
 	subsMaxInFlight  *prometheus.Desc
 }
 
-func newChannelsCollector(system string, servers []*CollectedServer) prometheus.Collector {
+func newChannelsCollector(system string, servers []*CollectedServer, transport http.RoundTripper) prometheus.Collector {
 	subsVariableLabels := []string{
 		"server_id", "server_role", "channel", "client_id", "inbox", "queue_name",
 		"is_durable", "is_offline", "durable_name",
 	}
+	descs := &metricDescs{}
 	nc := &channelsCollector{
-		httpClient: http.DefaultClient,
-		system:     system,
-		chanBytesTotal: prometheus.NewDesc(
+		metricDescs: descs,
+		httpClient:  &http.Client{Transport: transport},
+		system:      system,
+		chanBytesTotal: descs.gauge(
 			prometheus.BuildFQName(system, "chan", "bytes_total"),
 			"Total of bytes",
 			[]string{"server_id", "server_role", "channel"},
-			nil,
 		),
-		chanMsgsTotal: prometheus.NewDesc(
+		chanMsgsTotal: descs.gauge(
 			prometheus.BuildFQName(system, "chan", "msgs_total"),
 			"Total of messages",
 			[]string{"server_id", "server_role", "channel"},
-			nil,
 		),
-		chanLastSeq: prometheus.NewDesc(
+		chanLastSeq: descs.gauge(
 			prometheus.BuildFQName(system, "chan", "last_seq"),
 			"Last seq",
 			[]string{"server_id", "server_role", "channel"},
-			nil,
 		),
-		subsLastSent: prometheus.NewDesc(
+		subsLastSent: descs.gauge(
 			prometheus.BuildFQName(system, "chan", "subs_last_sent"),
 			"Last message sent",
 			subsVariableLabels,
-			nil,
 		),
-		subsPendingCount: prometheus.NewDesc(
+		subsPendingCount: descs.gauge(
 			prometheus.BuildFQName(system, "chan", "subs_pending_count"),
 			"Pending message count",
 			subsVariableLabels,
-			nil,
 		),
-		subsMaxInFlight: prometheus.NewDesc(
+		subsMaxInFlight: descs.gauge(
 			prometheus.BuildFQName(system, "chan", "subs_max_inflight"),
 			"Max in flight message count",
 			subsVariableLabels,
-			nil,
 		),
 	}
 
@@ -290,15 +265,6 @@ func newChannelsCollector(system string, servers []*CollectedServer) prometheus.
 	return nc
 }
 
-func (nc *channelsCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- nc.chanBytesTotal
-	ch <- nc.chanMsgsTotal
-	ch <- nc.chanLastSeq
-	ch <- nc.subsLastSent
-	ch <- nc.subsPendingCount
-	ch <- nc.subsMaxInFlight
-}
-
 func getRoleFromChannelszURL(client *http.Client, url string) (string, error) {
 	if !strings.HasSuffix(url, channelszSuffix) {
 		return "", nil
@@ -0,0 +1,95 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Transport is the round tripper of the requests of collectors to the
+// monitoring endpoints of the servers.  It limits the requests running at
+// once to each server, trusts the pinned certificates of the servers, and
+// records the round trip time of the requests, which the fetch_rtt
+// collector of the same transport reports.  It may present a client
+// certificate, loaded again when its files change.  Its state is kept until it is
+// closed.
+type Transport struct {
+	mu     sync.Mutex
+	base   *http.Transport
+	pinned map[string]*http.Transport
+	limit  int
+	slots  map[string]chan struct{}
+	rtts   map[string]time.Duration
+}
+
+// NewTransport returns a transport running at most endpointConcurrency
+// requests to each server at once, if positive.
+func NewTransport(endpointConcurrency int) *Transport {
+	return &Transport{
+		base:   http.DefaultTransport.(*http.Transport).Clone(),
+		pinned: make(map[string]*http.Transport),
+		limit:  endpointConcurrency,
+		slots:  make(map[string]chan struct{}),
+		rtts:   make(map[string]time.Duration),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.  The request counts against the
+// concurrency limit of its server until the response body is closed.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release := t.acquireEndpointSlot(req.URL.Host)
+	start := time.Now()
+	resp, err := t.hostTransport(req.URL).RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	t.recordFetchRTT(req.URL.String(), time.Since(start))
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// hostTransport returns the pinned transport of the server of a URL over
+// HTTPS, if any, and the base transport otherwise.
+func (t *Transport) hostTransport(u *url.URL) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.pinned[u.Host]; ok && u.Scheme == "https" {
+		return p
+	}
+	return t.base
+}
+
+// Close closes the idle connections of the transport, and drops the pins,
+// the concurrency limits and the round trip times of the servers.
+func (t *Transport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base.CloseIdleConnections()
+	for _, p := range t.pinned {
+		p.CloseIdleConnections()
+	}
+	t.pinned = make(map[string]*http.Transport)
+	t.slots = make(map[string]chan struct{})
+	t.rtts = make(map[string]time.Duration)
+}
+
+// transportOf returns the transport of a client, if it is one.
+func transportOf(httpClient *http.Client) (*Transport, bool) {
+	t, ok := httpClient.Transport.(*Transport)
+	return t, ok
+}
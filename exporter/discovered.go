@@ -0,0 +1,81 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// discoveredCollector reports when each server polled was added to the
+// servers polled, which change with the targets file and the scans.
+type discoveredCollector struct {
+	sync.Mutex
+	desc  *collector.MetricDesc
+	since map[string]time.Time
+}
+
+func newDiscoveredCollector() *discoveredCollector {
+	return &discoveredCollector{
+		desc: collector.NewMetricDesc(prometheus.GaugeValue,
+			prometheus.BuildFQName(collector.ExporterSystem, "server", "discovered_timestamp_seconds"),
+			"Time the server was added to the servers polled, in seconds since the epoch",
+			[]string{"server"},
+		),
+		since: make(map[string]time.Time),
+	}
+}
+
+// update sets the servers polled.  The servers already polled keep the
+// time they were added, the others are added now and the servers no
+// longer polled are forgotten, so that they are added anew if found again.
+func (dc *discoveredCollector) update(servers []*collector.CollectedServer) {
+	dc.Lock()
+	defer dc.Unlock()
+
+	now := time.Now()
+	since := make(map[string]time.Time, len(servers))
+	for _, cs := range servers {
+		if t, ok := dc.since[cs.ID]; ok {
+			since[cs.ID] = t
+		} else {
+			since[cs.ID] = now
+		}
+	}
+	dc.since = since
+}
+
+// Describe implements prometheus.Collector.
+func (dc *discoveredCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dc.desc.Desc
+}
+
+// MetricDescs implements collector.MetricDescriber.
+func (dc *discoveredCollector) MetricDescs() []*collector.MetricDesc {
+	return []*collector.MetricDesc{dc.desc}
+}
+
+// Collect implements prometheus.Collector.
+func (dc *discoveredCollector) Collect(ch chan<- prometheus.Metric) {
+	dc.Lock()
+	defer dc.Unlock()
+
+	for id, t := range dc.since {
+		ch <- prometheus.MustNewConstMetric(dc.desc.Desc, prometheus.GaugeValue,
+			float64(t.UnixNano())/float64(time.Second), id)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"strings"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// legacyNamesCollector reports the metrics of a collector using a prefix
+// under their unprefixed names too, so that the queries using them keep
+// working while migrating to the prefix.
+type legacyNamesCollector struct {
+	prometheus.Collector
+
+	// descs are the descriptions of the metrics under their unprefixed
+	// names, keyed by the description of the metrics they copy.
+	descs  []*collector.MetricDesc
+	legacy map[*prometheus.Desc]*collector.MetricDesc
+}
+
+func newLegacyNamesCollector(c prometheus.Collector, prefix, system string) *legacyNamesCollector {
+	lc := &legacyNamesCollector{
+		Collector: c,
+		legacy:    make(map[*prometheus.Desc]*collector.MetricDesc),
+	}
+	for _, d := range metricDescs(c) {
+		if !strings.HasPrefix(d.Name, prefix+"_") {
+			continue
+		}
+		name := system + "_" + strings.TrimPrefix(d.Name, prefix+"_")
+		legacy := collector.NewMetricDesc(d.Type, name, d.Help, d.Labels)
+		lc.descs = append(lc.descs, legacy)
+		lc.legacy[d.Desc] = legacy
+	}
+	return lc
+}
+
+// Describe describes the metrics of the collector, along with their copies
+// under their unprefixed names.
+func (lc *legacyNamesCollector) Describe(ch chan<- *prometheus.Desc) {
+	lc.Collector.Describe(ch)
+	collector.DescribeMetricDescs(lc.descs, ch)
+}
+
+// MetricDescs implements collector.MetricDescriber.
+func (lc *legacyNamesCollector) MetricDescs() []*collector.MetricDesc {
+	return append(append([]*collector.MetricDesc(nil), metricDescs(lc.Collector)...), lc.descs...)
+}
+
+// Collect collects the metrics of the collector, along with a copy of each
+// of them under its unprefixed name.
+func (lc *legacyNamesCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		lc.Collector.Collect(metrics)
+		close(metrics)
+	}()
+	for m := range metrics {
+		ch <- m
+		if legacy, ok := lc.legacyMetric(m); ok {
+			ch <- legacy
+		}
+	}
+}
+
+// legacyMetric returns a copy of a gauge, counter or untyped metric under
+// its unprefixed name.
+func (lc *legacyNamesCollector) legacyMetric(m prometheus.Metric) (prometheus.Metric, bool) {
+	desc, ok := lc.legacy[m.Desc()]
+	if !ok {
+		return nil, false
+	}
+	var pb dto.Metric
+	if err := m.Write(&pb); err != nil {
+		return nil, false
+	}
+	var value float64
+	switch {
+	case desc.Type == prometheus.GaugeValue && pb.Gauge != nil:
+		value = pb.Gauge.GetValue()
+	case desc.Type == prometheus.CounterValue && pb.Counter != nil:
+		value = pb.Counter.GetValue()
+	case desc.Type == prometheus.UntypedValue && pb.Untyped != nil:
+		value = pb.Untyped.GetValue()
+	default:
+		return nil, false
+	}
+
+	labels := make(map[string]string, len(pb.Label))
+	for _, lp := range pb.Label {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	values := make([]string, len(desc.Labels))
+	for i, l := range desc.Labels {
+		values[i] = labels[l]
+	}
+	legacy, err := prometheus.NewConstMetric(desc.Desc, desc.Type, value, values...)
+	return legacy, err == nil
+}
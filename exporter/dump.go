@@ -0,0 +1,76 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// DefaultDumpInterval is the interval at which the metrics are written to
+// the dump file when none is set.
+var DefaultDumpInterval = time.Minute
+
+// dumpMetrics writes the metrics to the dump file on start, then at each
+// interval, until done is closed.
+func (ne *NATSExporter) dumpMetrics(path string, interval time.Duration, done chan struct{}) {
+	if interval <= 0 {
+		interval = DefaultDumpInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := writeMetricsFile(path, ne.gatherer()); err != nil {
+			collector.Errorf("Unable to dump the metrics to %s: %v", path, err)
+		}
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// writeMetricsFile writes the gathered metrics to a file in the text
+// exposition format.  The metrics are written to a temporary file first,
+// which then replaces the file, so that readers never see a partial dump.
+func writeMetricsFile(path string, g prometheus.Gatherer) error {
+	mfs, err := g.Gather()
+	if err != nil {
+		collector.Debugf("Gathered metrics with errors for the dump: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := expfmt.NewEncoder(tmp, expfmt.FmtText)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
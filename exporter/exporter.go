@@ -30,6 +30,7 @@ import (
 	"github.com/nats-io/prometheus-nats-exporter/collector"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/model"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -41,6 +42,7 @@ const (
 // NATSExporterOptions are options to configure the NATS collector
 type NATSExporterOptions struct {
 	collector.LoggerOptions
+	collector.CollectorOptions
 	ListenAddress        string
 	ListenPort           int
 	ScrapePath           string
@@ -56,6 +58,12 @@ type NATSExporterOptions struct {
 	GetStreamingChannelz bool
 	GetStreamingServerz  bool
 	GetJszFilter         string
+	GetAccountEvents     bool
+	GetAccountz          bool
+	GetFetchRTT          bool
+	GetConfiguredServers bool
+	GetScrapeStreak      bool
+	GetHeap              bool
 	RetryInterval        time.Duration
 	CertFile             string
 	KeyFile              string
@@ -67,6 +75,32 @@ type NATSExporterOptions struct {
 	Prefix               string
 	UseInternalServerID  bool
 	UseServerName        bool
+	DedupByServerID      bool            // Scrape servers sharing the same server_id only once.
+	PublicListen         string          // Optional host:port serving only the public metrics.
+	PublicMetrics        []string        // Patterns of the metric names served publicly.
+	TargetsFile          string          // Prometheus file_sd JSON file listing the servers.
+	LabelSanitizer       LabelSanitizer  // Optional rewrite of all the label values.
+	SampleProcessor      SampleProcessor // Optional rewrite or removal of all the samples.
+	SuppressZeroValues   bool            // Leave out the gauge samples whose value is zero.
+	ServerLabelName      string          // Name of the label identifying the servers, server_id by default.
+	DualEmit             bool            // Also serve the metrics under their unprefixed names.
+	ScanCIDR             string          // Optional range of addresses scanned for servers.
+	ScanPort             int             // Monitoring port scanned, DefaultScanPort by default.
+	ScanInterval         time.Duration   // Interval between the scans, DefaultScanInterval by default.
+	ScanRate             int             // Addresses probed per second, DefaultScanRate by default.
+	DebugLastResponse    bool            // Serve the last response of each endpoint.
+	ClusterLabel         bool            // Add the cluster name from varz to all the metrics.
+	InstanceLabel        bool            // Add an exporter_instance label to all the metrics.
+	InstanceName         string          // Value of the exporter_instance label, the hostname by default.
+	RecoverPanics        bool            // Keep serving the other metrics when a collector panics.
+	DumpFile             string          // Optional file the metrics are written to periodically.
+	DumpInterval         time.Duration   // Interval between the dumps, DefaultDumpInterval by default.
+	FailureGracePeriod   time.Duration   // Keep reporting the last metrics of a failed server this long.
+	CountHTTPRequests    bool            // Count the requests served by the exporter, by path and code.
+	SystemCredsFile      string          // Credentials file of the system account user of nats URLs.
+	SystemNKeyFile       string          // Nkey seed file of the system account user of nats URLs.
+	MonitorCertFile      string          // Client certificate presented to the servers over HTTPS.
+	MonitorKeyFile       string          // Key of the client certificate presented to the servers.
 }
 
 // NATSExporter collects NATS metrics
@@ -75,11 +109,29 @@ type NATSExporter struct {
 	opts       *NATSExporterOptions
 	doneWg     sync.WaitGroup
 	http       net.Listener
+	publicHTTP net.Listener
 	Collectors []prometheus.Collector
 	servers    []*collector.CollectedServer
 	mode       uint8
+
+	targetLabels   map[string]map[string]string
+	targetsDone    chan struct{}
+	scanDone       chan struct{}
+	dumpDone       chan struct{}
+	clusters       map[string]string
+	clusterRetries map[string]clusterRetry
+	transport      *collector.Transport
+	instance       string
+	panics         *counterVec
+	stale          *staleCollector
+	httpRequests   *counterVec
+	discovered     *discoveredCollector
 }
 
+// LastResponsePath is the path serving the last response received from a
+// server endpoint, when enabled.
+const LastResponsePath = "/debug/lastresponse"
+
 // Defaults
 var (
 	DefaultListenPort        = 7777
@@ -104,6 +156,18 @@ func GetDefaultExporterOptions() *NATSExporterOptions {
 	return opts
 }
 
+// ApplyEdgePreset configures opts to monitor an edge server: general and
+// leaf node metrics, along with the JetStream stream metrics of the given
+// domain unless another jsz filter is set.
+func ApplyEdgePreset(opts *NATSExporterOptions, domain string) {
+	opts.GetVarz = true
+	opts.GetLeafz = true
+	if opts.GetJszFilter == "" {
+		opts.GetJszFilter = "streams"
+	}
+	opts.JszDomain = domain
+}
+
 // NewExporter creates a new NATS exporter
 func NewExporter(opts *NATSExporterOptions) *NATSExporter {
 	o := opts
@@ -122,11 +186,143 @@ func NewExporter(opts *NATSExporterOptions) *NATSExporter {
 	return ne
 }
 
+// namedCollector is a collector along with the system and endpoint it polls.
+type namedCollector struct {
+	system    string
+	endpoint  string
+	collector prometheus.Collector
+}
+
+// newCollector creates a collector polling the servers through the
+// transport, wrapped as the options require.  The shared collectors must
+// have been created first.
+func (ne *NATSExporter) newCollector(system, endpoint string, servers []*collector.CollectedServer,
+	transport *collector.Transport) prometheus.Collector {
+	opts := ne.opts.CollectorOptions
+	opts.Transport = transport
+	nc := collector.NewCollectorWithOptions(system, endpoint,
+		ne.opts.Prefix,
+		servers,
+		&opts)
+	if ne.opts.RecoverPanics {
+		nc = &recoveringCollector{Collector: nc, name: system + "/" + endpoint, panics: ne.panics}
+	}
+	if ne.opts.DualEmit && ne.opts.Prefix != "" && system != collector.ExporterSystem {
+		nc = newLegacyNamesCollector(nc, ne.opts.Prefix, system)
+	}
+	if ne.opts.FailureGracePeriod > 0 {
+		nc = newGraceCollector(nc, system+"/"+endpoint, ne.opts.FailureGracePeriod, ne.stale)
+	}
+	return nc
+}
+
+// initSharedCollectors creates the collectors shared by all the others,
+// which are kept when the collectors are replaced.
+// Caller must lock
+func (ne *NATSExporter) initSharedCollectors() {
+	if ne.opts.RecoverPanics && ne.panics == nil {
+		ne.panics = newCollectorPanicsCounter()
+	}
+	if ne.opts.FailureGracePeriod > 0 && ne.stale == nil {
+		ne.stale = newStaleCollector()
+	}
+	if ne.opts.CountHTTPRequests && ne.httpRequests == nil {
+		ne.httpRequests = newHTTPRequestsCounter()
+	}
+	// The servers polled only change with a targets file or a scan.
+	if (ne.opts.TargetsFile != "" || ne.opts.ScanCIDR != "") && ne.discovered == nil {
+		ne.discovered = newDiscoveredCollector()
+	}
+}
+
+// sharedCollectors returns the shared collectors enabled.
+func (ne *NATSExporter) sharedCollectors() []prometheus.Collector {
+	var shared []prometheus.Collector
+	if ne.panics != nil {
+		shared = append(shared, ne.panics)
+	}
+	if ne.stale != nil {
+		shared = append(shared, ne.stale)
+	}
+	if ne.httpRequests != nil {
+		shared = append(shared, ne.httpRequests)
+	}
+	if ne.discovered != nil {
+		shared = append(shared, ne.discovered)
+	}
+	return shared
+}
+
 func (ne *NATSExporter) createCollector(system, endpoint string) {
-	ne.registerCollector(system, endpoint,
-		collector.NewCollector(system, endpoint,
-			ne.opts.Prefix,
-			ne.servers))
+	ne.registerCollector(system, endpoint, ne.newCollector(system, endpoint, ne.servers, ne.transport))
+}
+
+// checkCollectorConflicts returns an error listing the fully qualified
+// metric names described by more than one of the collectors, which would
+// otherwise fail registration or scrapes later on.
+func checkCollectorConflicts(collectors []*namedCollector) error {
+	owners := make(map[string][]string)
+	var names []string
+	for _, nc := range collectors {
+		owner := nc.system + "/" + nc.endpoint
+		seen := make(map[string]struct{})
+		for _, d := range metricDescs(nc.collector) {
+			name := d.Name
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			if _, ok := owners[name]; !ok {
+				names = append(names, name)
+			}
+			owners[name] = append(owners[name], owner)
+		}
+	}
+
+	var conflicts []string
+	for _, name := range names {
+		if len(owners[name]) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", name, strings.Join(owners[name], ", ")))
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("collectors report conflicting metrics: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// collectorLabelNames returns the names of the labels of the metrics the
+// collectors describe.
+func collectorLabelNames(collectors []prometheus.Collector) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, c := range collectors {
+		for _, d := range metricDescs(c) {
+			for _, l := range d.Labels {
+				names[l] = struct{}{}
+			}
+		}
+	}
+	return names
+}
+
+// checkServerLabelName returns an error when the servers are to be
+// identified with a label the metrics have already.
+func (ne *NATSExporter) checkServerLabelName() error {
+	name := ne.serverLabelName()
+	if name == serverIDLabel {
+		return nil
+	}
+	taken := collectorLabelNames(ne.Collectors)
+	if ne.instance != "" {
+		taken["exporter_instance"] = struct{}{}
+	}
+	if ne.opts.ClusterLabel {
+		taken["cluster"] = struct{}{}
+	}
+	if _, ok := taken[name]; ok {
+		return fmt.Errorf("server label name %q is already a label of the metrics", name)
+	}
+	return nil
 }
 
 func (ne *NATSExporter) registerCollector(system, endpoint string, nc prometheus.Collector) {
@@ -145,6 +341,11 @@ func (ne *NATSExporter) registerCollector(system, endpoint string, nc prometheus
 	} else {
 		collector.Debugf("Registered collector for system %s, endpoint: %s", system, endpoint)
 		ne.Collectors = append(ne.Collectors, nc)
+		if ec, ok := eventCollector(nc); ok {
+			if err := ec.Start(); err != nil {
+				collector.Errorf("Unable to start collecting events for endpoint %s: %v", endpoint, err)
+			}
+		}
 	}
 }
 
@@ -152,13 +353,16 @@ func (ne *NATSExporter) registerCollector(system, endpoint string, nc prometheus
 // through the options.  Adding more than one server will
 // violate Prometheus.io guidelines.
 func (ne *NATSExporter) AddServer(id, url string) error {
+	return ne.addServer(&collector.CollectedServer{ID: id, URL: url})
+}
+
+func (ne *NATSExporter) addServer(cs *collector.CollectedServer) error {
 	ne.Lock()
 	defer ne.Unlock()
 
 	if ne.mode == modeStarted {
 		return fmt.Errorf("servers cannot be added after the exporter is started")
 	}
-	cs := &collector.CollectedServer{ID: id, URL: url}
 	if ne.servers == nil {
 		ne.servers = make([]*collector.CollectedServer, 0)
 	}
@@ -166,77 +370,294 @@ func (ne *NATSExporter) AddServer(id, url string) error {
 	return nil
 }
 
-// InitializeCollectors initializes the Collectors for the exporter.
-// Caller must lock
-func (ne *NATSExporter) InitializeCollectors() error {
+// AddPinnedServer adds a server like AddServer, trusting its certificate
+// with the given SHA-256 fingerprint over HTTPS instead of verifying it
+// against the CAs.
+func (ne *NATSExporter) AddPinnedServer(id, url, fingerprint string) error {
+	if _, err := collector.ParseSHA256Fingerprint(fingerprint); err != nil {
+		return err
+	}
+	return ne.addServer(&collector.CollectedServer{ID: id, URL: url, TLSPinnedSHA256: fingerprint})
+}
+
+// dedupServers drops the servers reporting the same server_id in /varz
+// as a server configured before them, e.g. the same server listed under
+// two DNS names. Servers which cannot be queried are kept.
+func dedupServers(transport *collector.Transport, configured []*collector.CollectedServer) []*collector.CollectedServer {
+	seen := make(map[string]*collector.CollectedServer)
+	servers := make([]*collector.CollectedServer, 0, len(configured))
+	for _, cs := range configured {
+		id, err := transport.QueryServerIDFromVarz(cs.URL)
+		if err != nil {
+			collector.Debugf("Unable to get the server_id of %s: %v", cs.URL, err)
+			servers = append(servers, cs)
+			continue
+		}
+		if prev, ok := seen[id]; ok {
+			collector.Noticef("Server %s (%s) is the same server as %s (%s), skipping",
+				cs.ID, cs.URL, prev.ID, prev.URL)
+			continue
+		}
+		seen[id] = cs
+		servers = append(servers, cs)
+	}
+	return servers
+}
+
+// collectorSet is the collectors created for a set of servers, and not
+// registered yet, along with the transport of their requests.
+type collectorSet struct {
+	servers    []*collector.CollectedServer
+	collectors []*namedCollector
+	transport  *collector.Transport
+}
+
+// list returns the collectors of the set.
+func (set *collectorSet) list() []prometheus.Collector {
+	collectors := make([]prometheus.Collector, 0, len(set.collectors))
+	for _, nc := range set.collectors {
+		collectors = append(collectors, nc.collector)
+	}
+	return collectors
+}
+
+// newTransport returns a transport for the requests to the servers,
+// presenting the client certificate of the options, if any.
+func (ne *NATSExporter) newTransport() (*collector.Transport, error) {
+	transport := collector.NewTransport(ne.opts.EndpointConcurrency)
+	if ne.opts.MonitorCertFile != "" || ne.opts.MonitorKeyFile != "" {
+		if err := transport.SetClientCertificate(ne.opts.MonitorCertFile, ne.opts.MonitorKeyFile); err != nil {
+			return nil, err
+		}
+	}
+	return transport, nil
+}
+
+// buildCollectors creates and validates the enabled collectors polling the
+// servers, without registering them.  The shared collectors must have been
+// created first; the exporter does not need to be locked.
+func (ne *NATSExporter) buildCollectors(servers []*collector.CollectedServer) (*collectorSet, error) {
+	transport, err := ne.newTransport()
+	if err != nil {
+		return nil, err
+	}
+	set, err := ne.buildCollectorsWithTransport(servers, transport)
+	if err != nil {
+		transport.Close()
+		return nil, err
+	}
+	return set, nil
+}
+
+func (ne *NATSExporter) buildCollectorsWithTransport(servers []*collector.CollectedServer,
+	transport *collector.Transport) (*collectorSet, error) {
 	opts := ne.opts
 
-	if len(ne.servers) == 0 {
-		return fmt.Errorf("no servers configured to obtain metrics")
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers configured to obtain metrics")
 	}
 
 	getJsz := opts.GetJszFilter != ""
 	if !opts.GetHealthz && !opts.GetConnz && !opts.GetConnzDetailed && !opts.GetRoutez &&
-		!opts.GetSubz && !opts.GetVarz && !opts.GetGatewayz && !opts.GetLeafz &&
+		!opts.GetSubz && !opts.GetVarz && !opts.GetGatewayz && !opts.GetLeafz && !opts.GetAccountEvents &&
+		!opts.GetAccountz &&
 		!opts.GetStreamingChannelz && !opts.GetStreamingServerz && !opts.GetReplicatorVarz && !getJsz {
-		return fmt.Errorf("no Collectors specfied")
+		return nil, fmt.Errorf("no Collectors specfied")
 	}
 	if opts.GetReplicatorVarz && opts.GetVarz {
-		return fmt.Errorf("replicatorVarz cannot be used with varz")
+		return nil, fmt.Errorf("replicatorVarz cannot be used with varz")
+	}
+	servers = collector.DiscoverSystemServers(servers)
+	transport.PinServerCertificates(servers)
+	if opts.DedupByServerID {
+		servers = dedupServers(transport, servers)
+	}
+
+	var collectors []*namedCollector
+	add := func(system, endpoint string) {
+		collectors = append(collectors, &namedCollector{
+			system:    system,
+			endpoint:  endpoint,
+			collector: ne.newCollector(system, endpoint, servers, transport),
+		})
 	}
 	if opts.GetSubz {
-		ne.createCollector(collector.CoreSystem, "subsz")
+		add(collector.CoreSystem, "subsz")
 	}
 	if opts.GetVarz {
-		ne.createCollector(collector.CoreSystem, "varz")
+		add(collector.CoreSystem, "varz")
 	}
 	if opts.GetHealthz {
-		ne.createCollector(collector.CoreSystem, "healthz")
+		add(collector.CoreSystem, "healthz")
 	}
 	if opts.GetConnzDetailed {
-		ne.createCollector(collector.CoreSystem, "connz_detailed")
+		add(collector.CoreSystem, "connz_detailed")
 	} else if opts.GetConnz {
-		ne.createCollector(collector.CoreSystem, "connz")
+		add(collector.CoreSystem, "connz")
 	}
 	if opts.GetGatewayz {
-		ne.createCollector(collector.CoreSystem, "gatewayz")
+		add(collector.CoreSystem, "gatewayz")
 	}
 	if opts.GetLeafz {
-		ne.createCollector(collector.CoreSystem, "leafz")
+		add(collector.CoreSystem, "leafz")
 	}
 	if opts.GetRoutez {
-		ne.createCollector(collector.CoreSystem, "routez")
+		add(collector.CoreSystem, "routez")
+	}
+	if opts.GetAccountz {
+		add(collector.CoreSystem, "accountz")
+	}
+	if opts.GetAccountEvents {
+		add(collector.CoreSystem, "account_events")
+	}
+	if opts.GetFetchRTT {
+		add(collector.ExporterSystem, "fetch_rtt")
+	}
+	if opts.GetConfiguredServers {
+		add(collector.ExporterSystem, "configured_servers")
+	}
+	if opts.GetScrapeStreak {
+		add(collector.ExporterSystem, "scrape_streak")
+	}
+	if opts.GetHeap {
+		add(collector.ExporterSystem, "heap")
 	}
 	if opts.GetStreamingChannelz {
-		ne.createCollector(collector.StreamingSystem, "channelsz")
+		add(collector.StreamingSystem, "channelsz")
 	}
 	if opts.GetStreamingServerz {
-		ne.createCollector(collector.StreamingSystem, "serverz")
+		add(collector.StreamingSystem, "serverz")
 	}
 	if opts.GetReplicatorVarz {
-		ne.createCollector(collector.ReplicatorSystem, "varz")
+		add(collector.ReplicatorSystem, "varz")
 	}
 	if getJsz {
 		switch strings.ToLower(opts.GetJszFilter) {
 		case "account", "accounts", "consumer", "consumers", "all", "stream", "streams":
 		default:
-			return fmt.Errorf("invalid jsz filter %q", opts.GetJszFilter)
+			return nil, fmt.Errorf("invalid jsz filter %q", opts.GetJszFilter)
+		}
+		add(collector.JetStreamSystem, opts.GetJszFilter)
+	}
+
+	// Validate all the collectors before registering any.
+	if err := checkCollectorConflicts(collectors); err != nil {
+		return nil, err
+	}
+	return &collectorSet{servers: servers, collectors: collectors, transport: transport}, nil
+}
+
+// InitializeCollectors initializes the Collectors for the exporter.
+// Caller must lock
+func (ne *NATSExporter) InitializeCollectors() error {
+	ne.initSharedCollectors()
+	set, err := ne.buildCollectors(ne.servers)
+	if err != nil {
+		return err
+	}
+	ne.servers = set.servers
+	ne.transport = set.transport
+	if ne.discovered != nil {
+		ne.discovered.update(ne.servers)
+	}
+	for _, nc := range set.collectors {
+		ne.registerCollector(nc.system, nc.endpoint, nc.collector)
+	}
+	for _, c := range ne.sharedCollectors() {
+		if err := prometheus.Register(c); err != nil {
+			collector.Errorf("Unable to register a shared collector: %v", err)
+		} else {
+			ne.Collectors = append(ne.Collectors, c)
+		}
+	}
+	return nil
+}
+
+// replaceCollectors replaces the registered collectors, except the shared
+// ones, with the collectors of the set, and closes the transport of the
+// collectors replaced.  The current collectors are kept, and the transport
+// of the set closed, when any of the new ones cannot be registered.
+// Caller must lock
+func (ne *NATSExporter) replaceCollectors(set *collectorSet) error {
+	shared := ne.sharedCollectors()
+	isShared := func(c prometheus.Collector) bool {
+		for _, s := range shared {
+			if c == s {
+				return true
+			}
+		}
+		return false
+	}
+	var current, kept []prometheus.Collector
+	for _, c := range ne.Collectors {
+		if isShared(c) {
+			kept = append(kept, c)
+		} else {
+			current = append(current, c)
 		}
-		ne.createCollector(collector.JetStreamSystem, opts.GetJszFilter)
 	}
 
+	for _, c := range current {
+		prometheus.Unregister(c)
+	}
+	registered := make([]prometheus.Collector, 0, len(set.collectors))
+	for _, nc := range set.collectors {
+		if err := prometheus.Register(nc.collector); err != nil {
+			for _, c := range registered {
+				prometheus.Unregister(c)
+			}
+			for _, c := range current {
+				if err := prometheus.Register(c); err != nil {
+					collector.Errorf("Unable to register the current collector again: %v", err)
+				}
+			}
+			set.transport.Close()
+			return fmt.Errorf("unable to register collector %s/%s: %v", nc.system, nc.endpoint, err)
+		}
+		registered = append(registered, nc.collector)
+	}
+
+	for _, c := range current {
+		if ec, ok := eventCollector(c); ok {
+			ec.Stop()
+		}
+	}
+	for _, nc := range set.collectors {
+		if ec, ok := eventCollector(nc.collector); ok {
+			if err := ec.Start(); err != nil {
+				collector.Errorf("Unable to start collecting events for endpoint %s: %v", nc.endpoint, err)
+			}
+		}
+	}
+	if ne.transport != nil {
+		ne.transport.Close()
+	}
+	ne.servers = set.servers
+	ne.transport = set.transport
+	if ne.discovered != nil {
+		ne.discovered.update(ne.servers)
+	}
+	ne.Collectors = append(registered, kept...)
 	return nil
 }
 
-// ClearCollectors unregisters the collectors
+// ClearCollectors unregisters the collectors, and closes the transport of
+// their requests
 // caller must lock
 func (ne *NATSExporter) ClearCollectors() {
 	if ne.Collectors != nil {
 		for _, c := range ne.Collectors {
 			prometheus.Unregister(c)
+			if ec, ok := eventCollector(c); ok {
+				ec.Stop()
+			}
 		}
 		ne.Collectors = nil
 	}
+	if ne.transport != nil {
+		ne.transport.Close()
+		ne.transport = nil
+	}
 }
 
 // Start runs the exporter process.
@@ -247,16 +668,73 @@ func (ne *NATSExporter) Start() error {
 		return nil
 	}
 
+	if !model.LabelName(ne.serverLabelName()).IsValid() {
+		return fmt.Errorf("invalid server label name %q", ne.opts.ServerLabelName)
+	}
+
+	if ne.opts.TargetsFile != "" && ne.opts.ScanCIDR != "" {
+		return fmt.Errorf("the targets file and the scan range cannot be used together")
+	}
+	if ne.opts.TargetsFile != "" {
+		if err := ne.loadTargets(); err != nil {
+			return err
+		}
+	}
+	if ne.opts.ScanCIDR != "" {
+		if err := ne.scanTargets(); err != nil {
+			return err
+		}
+	}
+
+	if ne.opts.InstanceLabel {
+		ne.instance = ne.opts.InstanceName
+		if ne.instance == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("unable to get the hostname for the instance label: %v", err)
+			}
+			ne.instance = hostname
+		}
+	}
+
+	if ne.opts.DebugLastResponse {
+		collector.RecordLastResponses(true)
+	}
+	if err := collector.ConfigureSystemAccount(ne.opts.SystemCredsFile, ne.opts.SystemNKeyFile); err != nil {
+		return err
+	}
+
 	if err := ne.InitializeCollectors(); err != nil {
 		ne.ClearCollectors()
 		return err
 	}
+	if err := ne.checkTargetLabels(ne.targetLabels, ne.Collectors); err != nil {
+		ne.ClearCollectors()
+		return err
+	}
+	if err := ne.checkServerLabelName(); err != nil {
+		ne.ClearCollectors()
+		return err
+	}
 
 	if err := ne.startHTTP(); err != nil {
 		ne.ClearCollectors()
 		return fmt.Errorf("error serving http:  %v", err)
 	}
 
+	if ne.opts.TargetsFile != "" {
+		ne.targetsDone = make(chan struct{})
+		go ne.watchTargetsFile(ne.targetsDone)
+	}
+	if ne.opts.ScanCIDR != "" {
+		ne.scanDone = make(chan struct{})
+		go ne.rescanPeriodically(ne.scanDone)
+	}
+	if ne.opts.DumpFile != "" {
+		ne.dumpDone = make(chan struct{})
+		go ne.dumpMetrics(ne.opts.DumpFile, ne.opts.DumpInterval, ne.dumpDone)
+	}
+
 	ne.doneWg.Add(1)
 	ne.mode = modeStarted
 
@@ -318,12 +796,101 @@ func (ne *NATSExporter) isValidUserPass(user, password string) bool {
 	return true
 }
 
+// newHTTPRequestsCounter returns the counter of the requests served by the
+// exporter, for each of the paths it serves.
+func newHTTPRequestsCounter() *counterVec {
+	return newCounterVec("http_requests_total",
+		"Number of HTTP requests served by the exporter", []string{"path", "code"})
+}
+
 // getScrapeHandler returns the default handler if no nttp
 // auhtorization has been specificed.  Otherwise, it checks
 // basic authorization.
 func (ne *NATSExporter) getScrapeHandler() http.Handler {
-	h := promhttp.Handler()
+	return ne.withBasicAuth(promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(ne.gatherer(), promhttp.HandlerOpts{})))
+}
+
+// gatherer returns the gatherer of the metrics served by the exporter.
+func (ne *NATSExporter) gatherer() prometheus.Gatherer {
+	var g prometheus.Gatherer = &serverLabelsGatherer{Gatherer: prometheus.DefaultGatherer, labels: ne.serverLabels}
+	if ne.instance != "" {
+		g = &constLabelsGatherer{Gatherer: g, labels: map[string]string{"exporter_instance": ne.instance}}
+	}
+	if ne.opts.LabelSanitizer != nil {
+		g = &sanitizingGatherer{Gatherer: g, sanitize: ne.opts.LabelSanitizer}
+	}
+	if name := ne.serverLabelName(); name != serverIDLabel {
+		g = &renamingGatherer{Gatherer: g, from: serverIDLabel, to: name}
+	}
+	if ne.opts.SampleProcessor != nil {
+		g = &processingGatherer{Gatherer: g, process: ne.opts.SampleProcessor}
+	}
+	if ne.opts.SuppressZeroValues {
+		g = &zeroSuppressingGatherer{Gatherer: g}
+	}
+	return g
+}
+
+// serverIDLabel is the name of the label the collectors identify the
+// servers with.
+const serverIDLabel = "server_id"
+
+// serverLabelName returns the name of the label identifying the servers in
+// the metrics served.
+func (ne *NATSExporter) serverLabelName() string {
+	if ne.opts.ServerLabelName == "" {
+		return serverIDLabel
+	}
+	return ne.opts.ServerLabelName
+}
 
+// getPublicScrapeHandler returns a handler serving only the metrics
+// matching the public metric patterns.
+func (ne *NATSExporter) getPublicScrapeHandler() (http.Handler, error) {
+	g, err := newFilteredGatherer(ne.gatherer(), ne.opts.PublicMetrics)
+	if err != nil {
+		return nil, err
+	}
+	return ne.withBasicAuth(promhttp.HandlerFor(g, promhttp.HandlerOpts{})), nil
+}
+
+// handleLastResponse serves the last response body received from the
+// endpoint of a server, both given as query parameters.
+func (ne *NATSExporter) handleLastResponse(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("server")
+	endpoint := r.URL.Query().Get("endpoint")
+	if id == "" || endpoint == "" {
+		http.Error(w, "the server and endpoint parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	var monURL string
+	ne.Lock()
+	for _, cs := range ne.servers {
+		if cs.ID == id {
+			monURL = cs.URL
+			break
+		}
+	}
+	ne.Unlock()
+	if monURL == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, ok := collector.LastResponse(monURL + "/" + endpoint)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// withBasicAuth checks basic authorization before calling h if
+// http authorization has been specified.
+func (ne *NATSExporter) withBasicAuth(h http.Handler) http.Handler {
 	if ne.opts.HTTPUser != "" {
 		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 			auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
@@ -391,8 +958,28 @@ func (ne *NATSExporter) startHTTP() error {
 		return err
 	}
 
+	if ne.opts.PublicListen != "" {
+		if err := ne.startPublicHTTP(path, config); err != nil {
+			ne.http.Close()
+			return err
+		}
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle(path, ne.getScrapeHandler())
+	handle := func(pattern string, h http.Handler) {
+		if ne.opts.CountHTTPRequests {
+			h = promhttp.InstrumentHandlerCounter(
+				ne.httpRequests.MustCurryWith(prometheus.Labels{"path": pattern}), h)
+		}
+		mux.Handle(pattern, h)
+	}
+	handle(path, ne.getScrapeHandler())
+	if path != ManifestPath {
+		handle(ManifestPath, ne.withBasicAuth(http.HandlerFunc(ne.handleManifest)))
+	}
+	if ne.opts.DebugLastResponse {
+		handle(LastResponsePath, ne.withBasicAuth(http.HandlerFunc(ne.handleLastResponse)))
+	}
 
 	srv := &http.Server{
 		Addr:           hp,
@@ -424,6 +1011,47 @@ func (ne *NATSExporter) startHTTP() error {
 	return nil
 }
 
+// startPublicHTTP starts the HTTP server serving the public metrics, using
+// the same scrape path and TLS configuration as the main one.
+// caller must lock
+func (ne *NATSExporter) startPublicHTTP(path string, config *tls.Config) error {
+	h, err := ne.getPublicScrapeHandler()
+	if err != nil {
+		return err
+	}
+
+	hp := ne.opts.PublicListen
+	if config != nil {
+		ne.publicHTTP, err = tls.Listen("tcp", hp, config)
+	} else {
+		ne.publicHTTP, err = net.Listen("tcp", hp)
+	}
+	if err != nil {
+		collector.Errorf("can't start public HTTP listener: %v", err)
+		return err
+	}
+	collector.Noticef("Prometheus exporter serving public metrics at %s%s", hp, path)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, h)
+
+	srv := &http.Server{
+		Addr:           hp,
+		Handler:        mux,
+		MaxHeaderBytes: 1 << 20,
+		TLSConfig:      config,
+	}
+
+	sHTTP := ne.publicHTTP
+	go func() {
+		if err := srv.Serve(sHTTP); err != nil && ne.getMode() != modeStopped {
+			collector.Errorf("Public HTTP server stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
+
 func (ne *NATSExporter) getMode() uint8 {
 	ne.Lock()
 	mode := ne.mode
@@ -453,7 +1081,29 @@ func (ne *NATSExporter) Stop() {
 	if err := ne.http.Close(); err != nil {
 		collector.Debugf("Did not close HTTP: %v", err)
 	}
+	if ne.publicHTTP != nil {
+		if err := ne.publicHTTP.Close(); err != nil {
+			collector.Debugf("Did not close public HTTP: %v", err)
+		}
+		ne.publicHTTP = nil
+	}
+	if ne.targetsDone != nil {
+		close(ne.targetsDone)
+		ne.targetsDone = nil
+	}
+	if ne.scanDone != nil {
+		close(ne.scanDone)
+		ne.scanDone = nil
+	}
+	if ne.dumpDone != nil {
+		close(ne.dumpDone)
+		ne.dumpDone = nil
+	}
+	if ne.opts.DebugLastResponse {
+		collector.RecordLastResponses(false)
+	}
 	ne.ClearCollectors()
+	collector.CloseSystemConnections()
 	ne.doneWg.Done()
 	ne.mode = modeStopped
 }
@@ -0,0 +1,281 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// LabelSanitizer rewrites a label value before it is served.  It must
+// always return the same value for a given input, otherwise each scrape
+// would create new series.
+type LabelSanitizer func(value string) string
+
+var invalidLabelValueChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ReplaceInvalidLabelChars is a LabelSanitizer replacing every character
+// other than [a-zA-Z0-9_] with an underscore.
+func ReplaceInvalidLabelChars(value string) string {
+	return invalidLabelValueChars.ReplaceAllString(value, "_")
+}
+
+// sanitizingGatherer applies a LabelSanitizer to all the label values it
+// gathers.
+type sanitizingGatherer struct {
+	prometheus.Gatherer
+	sanitize LabelSanitizer
+}
+
+// Gather implements prometheus.Gatherer.
+func (sg *sanitizingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := sg.Gatherer.Gather()
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			for _, lp := range m.Label {
+				v := sg.sanitize(lp.GetValue())
+				lp.Value = &v
+			}
+		}
+	}
+	return mfs, err
+}
+
+// renamingGatherer renames a label of all the metrics it gathers.
+type renamingGatherer struct {
+	prometheus.Gatherer
+	from, to string
+}
+
+// Gather implements prometheus.Gatherer.
+func (rg *renamingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := rg.Gatherer.Gather()
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			renamed := false
+			for _, lp := range m.Label {
+				if lp.GetName() == rg.from {
+					to := rg.to
+					lp.Name = &to
+					renamed = true
+				}
+			}
+			if renamed {
+				sort.Slice(m.Label, func(i, j int) bool {
+					return m.Label[i].GetName() < m.Label[j].GetName()
+				})
+			}
+		}
+	}
+	return mfs, err
+}
+
+// SampleProcessor rewrites the value of a sample before it is served, given
+// the name and labels of its metric.  Returning false drops the sample.
+type SampleProcessor func(name string, labels map[string]string, value float64) (float64, bool)
+
+// processingGatherer applies a SampleProcessor to the gauge, counter and
+// untyped samples it gathers.
+type processingGatherer struct {
+	prometheus.Gatherer
+	process SampleProcessor
+}
+
+// Gather implements prometheus.Gatherer.
+func (pg *processingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := pg.Gatherer.Gather()
+	kept := mfs[:0]
+	for _, mf := range mfs {
+		metrics := mf.Metric[:0]
+		for _, m := range mf.Metric {
+			if pg.processSample(mf.GetName(), m) {
+				metrics = append(metrics, m)
+			}
+		}
+		mf.Metric = metrics
+		if len(mf.Metric) > 0 {
+			kept = append(kept, mf)
+		}
+	}
+	return kept, err
+}
+
+// processSample processes the value of a metric in place, returning whether
+// it is kept.
+func (pg *processingGatherer) processSample(name string, m *dto.Metric) bool {
+	var value *float64
+	switch {
+	case m.Gauge != nil:
+		value = m.Gauge.Value
+	case m.Counter != nil:
+		value = m.Counter.Value
+	case m.Untyped != nil:
+		value = m.Untyped.Value
+	default:
+		return true
+	}
+	labels := make(map[string]string, len(m.Label))
+	for _, lp := range m.Label {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	v, ok := pg.process(name, labels, *value)
+	*value = v
+	return ok
+}
+
+// zeroSuppressingGatherer leaves out the gauge and untyped samples whose
+// value is zero, keeping the counters as they are.
+type zeroSuppressingGatherer struct {
+	prometheus.Gatherer
+}
+
+// Gather implements prometheus.Gatherer.
+func (zg *zeroSuppressingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := zg.Gatherer.Gather()
+	kept := mfs[:0]
+	for _, mf := range mfs {
+		metrics := mf.Metric[:0]
+		for _, m := range mf.Metric {
+			if (m.Gauge != nil && m.Gauge.GetValue() == 0) || (m.Untyped != nil && m.Untyped.GetValue() == 0) {
+				continue
+			}
+			metrics = append(metrics, m)
+		}
+		mf.Metric = metrics
+		if len(mf.Metric) > 0 {
+			kept = append(kept, mf)
+		}
+	}
+	return kept, err
+}
+
+// filteredGatherer only gathers the metric families whose name fully
+// matches one of its patterns.
+type filteredGatherer struct {
+	prometheus.Gatherer
+	patterns []*regexp.Regexp
+}
+
+// newFilteredGatherer compiles the patterns used to select the metric
+// families gathered from g.
+func newFilteredGatherer(g prometheus.Gatherer, patterns []string) (*filteredGatherer, error) {
+	fg := &filteredGatherer{Gatherer: g}
+	for _, p := range patterns {
+		re, err := regexp.Compile("^(?:" + p + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric pattern %q: %v", p, err)
+		}
+		fg.patterns = append(fg.patterns, re)
+	}
+	return fg, nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (fg *filteredGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := fg.Gatherer.Gather()
+	filtered := mfs[:0]
+	for _, mf := range mfs {
+		if fg.matches(mf.GetName()) {
+			filtered = append(filtered, mf)
+		}
+	}
+	return filtered, err
+}
+
+func (fg *filteredGatherer) matches(name string) bool {
+	for _, re := range fg.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverLabelsGatherer adds the labels attached to each server to the
+// metrics reported for it, as identified by their server_id label.
+// Labels already set on a metric are left untouched, unless empty.
+type serverLabelsGatherer struct {
+	prometheus.Gatherer
+	labels func() map[string]map[string]string
+}
+
+// Gather implements prometheus.Gatherer.
+func (sg *serverLabelsGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := sg.Gatherer.Gather()
+	labels := sg.labels()
+	if len(labels) == 0 {
+		return mfs, err
+	}
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			addServerLabels(m, labels)
+		}
+	}
+	return mfs, err
+}
+
+func addServerLabels(m *dto.Metric, labels map[string]map[string]string) {
+	for _, lp := range m.Label {
+		if lp.GetName() == "server_id" {
+			addLabels(m, labels[lp.GetValue()])
+			return
+		}
+	}
+}
+
+// constLabelsGatherer adds the same labels to all the metrics it gathers.
+// Labels already set on a metric are left untouched, unless empty.
+type constLabelsGatherer struct {
+	prometheus.Gatherer
+	labels map[string]string
+}
+
+// Gather implements prometheus.Gatherer.
+func (cg *constLabelsGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := cg.Gatherer.Gather()
+	for _, mf := range mfs {
+		for _, m := range mf.Metric {
+			addLabels(m, cg.labels)
+		}
+	}
+	return mfs, err
+}
+
+func addLabels(m *dto.Metric, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	present := make(map[string]*dto.LabelPair, len(m.Label))
+	for _, lp := range m.Label {
+		present[lp.GetName()] = lp
+	}
+	for name, value := range labels {
+		name, value := name, value
+		// An empty label is the same as a missing one for Prometheus.
+		if lp, ok := present[name]; ok {
+			if lp.GetValue() == "" {
+				lp.Value = &value
+			}
+			continue
+		}
+		m.Label = append(m.Label, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	sort.Slice(m.Label, func(i, j int) bool {
+		return m.Label[i].GetName() < m.Label[j].GetName()
+	})
+}
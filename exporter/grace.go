@@ -0,0 +1,140 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// graceSnapshot is the last metrics a collector reported for a server.
+type graceSnapshot struct {
+	metrics []prometheus.Metric
+	at      time.Time
+}
+
+// graceCollector keeps reporting the last metrics of the servers a
+// collector no longer reports metrics for, until the grace period elapses.
+type graceCollector struct {
+	prometheus.Collector
+	name   string
+	period time.Duration
+	stale  *prometheus.Desc
+
+	mu   sync.Mutex
+	last map[string]*graceSnapshot
+}
+
+func newGraceCollector(c prometheus.Collector, name string, period time.Duration, stale *staleCollector) *graceCollector {
+	return &graceCollector{
+		Collector: c,
+		name:      name,
+		period:    period,
+		stale:     stale.desc.Desc,
+		last:      make(map[string]*graceSnapshot),
+	}
+}
+
+// staleCollector describes whether the metrics of the servers are stale,
+// which the grace collectors report along with their metrics.
+type staleCollector struct {
+	desc *collector.MetricDesc
+}
+
+func newStaleCollector() *staleCollector {
+	return &staleCollector{
+		desc: collector.NewMetricDesc(prometheus.GaugeValue,
+			prometheus.BuildFQName(collector.ExporterSystem, "", "value_stale"),
+			"Whether the metrics of the server are the last ones reported before a failure",
+			[]string{"collector", "server_id"},
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (sc *staleCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sc.desc.Desc
+}
+
+// MetricDescs implements collector.MetricDescriber.
+func (sc *staleCollector) MetricDescs() []*collector.MetricDesc {
+	return []*collector.MetricDesc{sc.desc}
+}
+
+// Collect implements prometheus.Collector.  The metrics are collected by
+// the grace collectors.
+func (sc *staleCollector) Collect(ch chan<- prometheus.Metric) {}
+
+// MetricDescs implements collector.MetricDescriber.
+func (gc *graceCollector) MetricDescs() []*collector.MetricDesc {
+	return metricDescs(gc.Collector)
+}
+
+// Collect collects the metrics of the collector, reporting the last metrics
+// of the servers missing from them during the grace period.
+func (gc *graceCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		gc.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	fresh := make(map[string][]prometheus.Metric)
+	for m := range metrics {
+		if id, ok := metricServerID(m); ok {
+			fresh[id] = append(fresh[id], m)
+		}
+		ch <- m
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	now := time.Now()
+	for id, ms := range fresh {
+		gc.last[id] = &graceSnapshot{metrics: ms, at: now}
+		ch <- prometheus.MustNewConstMetric(gc.stale, prometheus.GaugeValue, 0, gc.name, id)
+	}
+	for id, snap := range gc.last {
+		if _, ok := fresh[id]; ok {
+			continue
+		}
+		if now.Sub(snap.at) > gc.period {
+			delete(gc.last, id)
+			continue
+		}
+		for _, m := range snap.metrics {
+			ch <- m
+		}
+		ch <- prometheus.MustNewConstMetric(gc.stale, prometheus.GaugeValue, 1, gc.name, id)
+	}
+}
+
+// metricServerID returns the server_id label of a metric, if any.
+func metricServerID(m prometheus.Metric) (string, bool) {
+	var pb dto.Metric
+	if err := m.Write(&pb); err != nil {
+		return "", false
+	}
+	for _, lp := range pb.GetLabel() {
+		if lp.GetName() == "server_id" {
+			return lp.GetValue(), true
+		}
+	}
+	return "", false
+}
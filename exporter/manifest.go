@@ -0,0 +1,132 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ManifestPath is the path serving the description of the metrics of the
+// enabled collectors.
+const ManifestPath = "/manifest"
+
+// manifestMetric describes a metric of the manifest.
+type manifestMetric struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Help   string   `json:"help"`
+	Labels []string `json:"labels"`
+}
+
+// metricDescs returns the descriptions of the metrics of a collector.
+func metricDescs(c prometheus.Collector) []*collector.MetricDesc {
+	if md, ok := c.(collector.MetricDescriber); ok {
+		return md.MetricDescs()
+	}
+	return nil
+}
+
+// counterVec is a counter vector of the exporter, along with the
+// description of its metrics.
+type counterVec struct {
+	*prometheus.CounterVec
+	desc *collector.MetricDesc
+}
+
+func newCounterVec(name, help string, labels []string) *counterVec {
+	return &counterVec{
+		CounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: collector.ExporterSystem,
+			Name:      name,
+			Help:      help,
+		}, labels),
+		desc: collector.NewMetricDesc(prometheus.CounterValue,
+			prometheus.BuildFQName(collector.ExporterSystem, "", name), help, labels),
+	}
+}
+
+// MetricDescs implements collector.MetricDescriber.
+func (cv *counterVec) MetricDescs() []*collector.MetricDesc {
+	return []*collector.MetricDesc{cv.desc}
+}
+
+// metricTypeName returns the name of a metric type in the manifest.
+func metricTypeName(valueType prometheus.ValueType) string {
+	switch valueType {
+	case prometheus.CounterValue:
+		return "counter"
+	case prometheus.GaugeValue:
+		return "gauge"
+	default:
+		return "untyped"
+	}
+}
+
+// manifest describes the metrics of the collectors, from the descriptions
+// they keep, whether or not the servers are available.  The labels of a
+// metric reported with several sets of labels are all of them.
+func (ne *NATSExporter) manifest() []*manifestMetric {
+	ne.Lock()
+	collectors := append([]prometheus.Collector(nil), ne.Collectors...)
+	ne.Unlock()
+
+	metrics := make(map[string]*manifestMetric)
+	for _, c := range collectors {
+		for _, d := range metricDescs(c) {
+			m, ok := metrics[d.Name]
+			if !ok {
+				m = &manifestMetric{Name: d.Name, Type: metricTypeName(d.Type), Help: d.Help, Labels: []string{}}
+				metrics[d.Name] = m
+			}
+			for _, l := range d.Labels {
+				if l == serverIDLabel {
+					l = ne.serverLabelName()
+				}
+				if !containsString(m.Labels, l) {
+					m.Labels = append(m.Labels, l)
+				}
+			}
+		}
+	}
+
+	manifest := make([]*manifestMetric, 0, len(metrics))
+	for _, m := range metrics {
+		manifest = append(manifest, m)
+	}
+	sort.Slice(manifest, func(i, j int) bool {
+		return manifest[i].Name < manifest[j].Name
+	})
+	return manifest
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (ne *NATSExporter) handleManifest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"metrics": ne.manifest()}); err != nil {
+		collector.Errorf("Unable to write the manifest: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// recoveringCollector recovers from the panics of a collector, so that the
+// metrics of the other collectors are still served.
+type recoveringCollector struct {
+	prometheus.Collector
+	name   string
+	panics *counterVec
+}
+
+func newCollectorPanicsCounter() *counterVec {
+	return newCounterVec("collector_panics_total",
+		"Number of panics recovered from while collecting metrics", []string{"collector"})
+}
+
+// MetricDescs implements collector.MetricDescriber.
+func (rc *recoveringCollector) MetricDescs() []*collector.MetricDesc {
+	return metricDescs(rc.Collector)
+}
+
+// Collect collects the metrics of the collector, logging and counting the
+// panic it may cause.
+func (rc *recoveringCollector) Collect(ch chan<- prometheus.Metric) {
+	defer func() {
+		if r := recover(); r != nil {
+			collector.Errorf("Recovered from a panic collecting %s metrics: %v", rc.name, r)
+			rc.panics.WithLabelValues(rc.name).Inc()
+		}
+	}()
+	rc.Collector.Collect(ch)
+}
+
+// eventCollector returns the event collector behind a collector, if any.
+func eventCollector(c prometheus.Collector) (collector.EventCollector, bool) {
+	for {
+		switch w := c.(type) {
+		case *recoveringCollector:
+			c = w.Collector
+		case *graceCollector:
+			c = w.Collector
+		case *legacyNamesCollector:
+			c = w.Collector
+		default:
+			ec, ok := c.(collector.EventCollector)
+			return ec, ok
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+)
+
+// Scan defaults
+var (
+	DefaultScanPort     = 8222
+	DefaultScanInterval = 5 * time.Minute
+	DefaultScanRate     = 50
+)
+
+// maxScanBits is the number of host bits of the largest range scanned, a
+// /20 IPv4 range.
+const maxScanBits = 12
+
+// scanProbeTimeout is how long an address is given to answer a probe.
+var scanProbeTimeout = time.Second
+
+// scanAddresses returns the host addresses of a CIDR range.
+func scanAddresses(cidr string) ([]net.IP, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan range: %v", err)
+	}
+	ones, bits := ipnet.Mask.Size()
+	if bits-ones > maxScanBits {
+		return nil, fmt.Errorf("scan range %s has more than %d addresses", cidr, 1<<maxScanBits)
+	}
+	var ips []net.IP
+	for a := ip.Mask(ipnet.Mask); ipnet.Contains(a); a = nextIP(a) {
+		ips = append(ips, a)
+	}
+	// The network and broadcast addresses of an IPv4 range are not hosts.
+	if ip.To4() != nil && bits-ones >= 2 {
+		ips = ips[1 : len(ips)-1]
+	}
+	return ips, nil
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := append(net.IP(nil), ip...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// probeServer returns the server_id of the server whose varz a monitoring
+// URL serves, or an empty string.
+func probeServer(client *http.Client, monURL string) string {
+	resp, err := client.Get(monURL + "/varz")
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	var varz struct {
+		ID string `json:"server_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&varz); err != nil {
+		return ""
+	}
+	return varz.ID
+}
+
+// scanServers probes the monitoring port of each address of a CIDR range,
+// starting at most rate probes per second, and returns the servers which
+// answered.  Their ID is the server_id they reported.
+func scanServers(cidr string, port, rate int) ([]*collector.CollectedServer, error) {
+	ips, err := scanAddresses(cidr)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: scanProbeTimeout}
+	t := time.NewTicker(time.Second / time.Duration(rate))
+	defer t.Stop()
+
+	found := make([]string, len(ips))
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		if i > 0 {
+			<-t.C
+		}
+		wg.Add(1)
+		go func(i int, ip net.IP) {
+			defer wg.Done()
+			found[i] = probeServer(client, scanURL(ip, port))
+		}(i, ip)
+	}
+	wg.Wait()
+
+	var servers []*collector.CollectedServer
+	seen := make(map[string]struct{})
+	for i, ip := range ips {
+		if found[i] == "" {
+			continue
+		}
+		// A server answering on several addresses is polled once.
+		if _, ok := seen[found[i]]; ok {
+			continue
+		}
+		seen[found[i]] = struct{}{}
+		servers = append(servers, &collector.CollectedServer{ID: found[i], URL: scanURL(ip, port)})
+	}
+	return servers, nil
+}
+
+func scanURL(ip net.IP, port int) string {
+	return "http://" + net.JoinHostPort(ip.String(), strconv.Itoa(port))
+}
+
+// scanPort, scanRate and scanInterval return the scan settings, or their
+// default.
+func (ne *NATSExporter) scanPort() int {
+	if ne.opts.ScanPort > 0 {
+		return ne.opts.ScanPort
+	}
+	return DefaultScanPort
+}
+
+func (ne *NATSExporter) scanRate() int {
+	if ne.opts.ScanRate > 0 {
+		return ne.opts.ScanRate
+	}
+	return DefaultScanRate
+}
+
+func (ne *NATSExporter) scanInterval() time.Duration {
+	if ne.opts.ScanInterval > 0 {
+		return ne.opts.ScanInterval
+	}
+	return DefaultScanInterval
+}
+
+// scanTargets replaces the monitored servers with the ones found scanning
+// the scan range.
+// Caller must lock
+func (ne *NATSExporter) scanTargets() error {
+	servers, err := scanServers(ne.opts.ScanCIDR, ne.scanPort(), ne.scanRate())
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("no servers found scanning %s", ne.opts.ScanCIDR)
+	}
+	collector.Noticef("Found %d server(s) scanning %s", len(servers), ne.opts.ScanCIDR)
+	ne.servers = servers
+	return nil
+}
+
+// rescanPeriodically scans the scan range again at each scan interval, and
+// recreates the collectors when the servers found changed, until done is
+// closed.
+func (ne *NATSExporter) rescanPeriodically(done chan struct{}) {
+	t := time.NewTicker(ne.scanInterval())
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+		servers, err := scanServers(ne.opts.ScanCIDR, ne.scanPort(), ne.scanRate())
+		if err != nil || len(servers) == 0 {
+			collector.Errorf("No servers found scanning %s, keeping the current ones: %v", ne.opts.ScanCIDR, err)
+			continue
+		}
+
+		ne.Lock()
+		changed := !sameServers(ne.servers, servers)
+		ne.Unlock()
+		if !changed {
+			continue
+		}
+		// The collectors are created before locking, and replace the
+		// current ones only once they are all valid.
+		set, err := ne.buildCollectors(servers)
+		if err != nil {
+			collector.Errorf("Unable to create the collectors for the scanned servers, keeping the current ones: %v", err)
+			continue
+		}
+
+		ne.Lock()
+		select {
+		case <-done:
+			ne.Unlock()
+			return
+		default:
+		}
+		if err := ne.replaceCollectors(set); err != nil {
+			ne.Unlock()
+			collector.Errorf("Unable to replace the collectors for the scanned servers, keeping the current ones: %v", err)
+			continue
+		}
+		ne.Unlock()
+		collector.Noticef("Found %d server(s) scanning %s", len(servers), ne.opts.ScanCIDR)
+	}
+}
+
+func sameServers(a, b []*collector.CollectedServer) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].URL != b[i].URL {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,287 @@
+// Copyright 2023 The NATS Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nats-io/prometheus-nats-exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// targetsFilePollInterval is how often the targets file is checked for
+// changes.
+var targetsFilePollInterval = 5 * time.Second
+
+// clusterQueryBackoff is how long the cluster of a server is not queried
+// again after a failure, doubled after each failure up to
+// clusterQueryMaxBackoff.
+var (
+	clusterQueryBackoff    = time.Second
+	clusterQueryMaxBackoff = 5 * time.Minute
+)
+
+// clusterRetry is when the cluster of a server may be queried again after
+// a failure.
+type clusterRetry struct {
+	at      time.Time
+	backoff time.Duration
+}
+
+// fileSDTargetGroup is a target group of a Prometheus file_sd file.
+type fileSDTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// targetSet is the set of servers loaded from a targets file, along with
+// the labels attached to each of them, keyed by server ID.
+type targetSet struct {
+	servers []*collector.CollectedServer
+	labels  map[string]map[string]string
+}
+
+// loadTargetsFile parses a Prometheus file_sd JSON file.  Each target is
+// the monitoring host:port (or URL) of a server.  A server_id label sets
+// the ID of the servers in its group, which otherwise defaults to the
+// target URL without credentials.
+func loadTargetsFile(path string) (*targetSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var groups []fileSDTargetGroup
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("error parsing targets file %s: %v", path, err)
+	}
+
+	ts := &targetSet{labels: make(map[string]map[string]string)}
+	for _, g := range groups {
+		labels := make(map[string]string)
+		for k, v := range g.Labels {
+			if !model.LabelName(k).IsValid() || strings.HasPrefix(k, model.ReservedLabelPrefix) {
+				return nil, fmt.Errorf("invalid label name %q in %s", k, path)
+			}
+			if k != "server_id" {
+				labels[k] = v
+			}
+		}
+		for _, target := range g.Targets {
+			monURL := target
+			if !strings.Contains(monURL, "://") {
+				monURL = "http://" + monURL
+			}
+			u, err := url.ParseRequestURI(monURL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid target %q in %s: %v", target, path, err)
+			}
+			id := g.Labels["server_id"]
+			if id == "" {
+				id = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+			}
+			if _, ok := ts.labels[id]; ok {
+				return nil, fmt.Errorf("duplicate server %q in %s", id, path)
+			}
+			ts.servers = append(ts.servers, &collector.CollectedServer{ID: id, URL: monURL})
+			ts.labels[id] = labels
+		}
+	}
+	if len(ts.servers) == 0 {
+		return nil, fmt.Errorf("no targets found in %s", path)
+	}
+	return ts, nil
+}
+
+// loadTargets replaces the monitored servers with the ones from the
+// targets file.
+// Caller must lock
+func (ne *NATSExporter) loadTargets() error {
+	ts, err := loadTargetsFile(ne.opts.TargetsFile)
+	if err != nil {
+		return err
+	}
+	ne.servers = ts.servers
+	ne.targetLabels = ts.labels
+	return nil
+}
+
+// checkTargetLabels returns an error when a label of the targets file is
+// already set by the collectors, or names the servers.
+func (ne *NATSExporter) checkTargetLabels(labels map[string]map[string]string, collectors []prometheus.Collector) error {
+	taken := collectorLabelNames(collectors)
+	taken[ne.serverLabelName()] = struct{}{}
+	for _, l := range labels {
+		for name := range l {
+			if _, ok := taken[name]; ok {
+				return fmt.Errorf("label %q of the targets file is already set by the collectors", name)
+			}
+		}
+	}
+	return nil
+}
+
+// watchTargetsFile reloads the targets file and recreates the collectors
+// whenever the file is modified, until done is closed.
+func (ne *NATSExporter) watchTargetsFile(done chan struct{}) {
+	path := ne.opts.TargetsFile
+	lastMod := targetsFileModTime(path)
+
+	t := time.NewTicker(targetsFilePollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+		}
+		mod := targetsFileModTime(path)
+		if mod.Equal(lastMod) {
+			continue
+		}
+		lastMod = mod
+
+		ts, err := loadTargetsFile(path)
+		if err != nil {
+			collector.Errorf("Unable to reload targets, keeping the current ones: %v", err)
+			continue
+		}
+		// The collectors are created before locking, and replace the
+		// current ones only once they are all valid.
+		set, err := ne.buildCollectors(ts.servers)
+		if err == nil {
+			if err = ne.checkTargetLabels(ts.labels, set.list()); err != nil {
+				set.transport.Close()
+			}
+		}
+		if err != nil {
+			collector.Errorf("Unable to reload targets, keeping the current ones: %v", err)
+			continue
+		}
+
+		ne.Lock()
+		select {
+		case <-done:
+			ne.Unlock()
+			return
+		default:
+		}
+		if err := ne.replaceCollectors(set); err != nil {
+			ne.Unlock()
+			collector.Errorf("Unable to reload targets, keeping the current ones: %v", err)
+			continue
+		}
+		ne.targetLabels = ts.labels
+		ne.Unlock()
+		collector.Noticef("Reloaded %d target(s) from %s", len(ts.servers), path)
+	}
+}
+
+func targetsFileModTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// serverLabels returns the labels attached to each server, keyed by
+// server ID: the labels from the targets file, along with the cluster
+// label when enabled.
+func (ne *NATSExporter) serverLabels() map[string]map[string]string {
+	ne.Lock()
+	servers := ne.servers
+	targetLabels := ne.targetLabels
+	clusterLabel := ne.opts.ClusterLabel
+	ne.Unlock()
+
+	if !clusterLabel {
+		return targetLabels
+	}
+
+	clusters := ne.clusterNames(servers)
+	labels := make(map[string]map[string]string, len(servers))
+	for _, cs := range servers {
+		l := make(map[string]string, len(targetLabels[cs.ID])+1)
+		if name := clusters[cs.ID]; name != "" {
+			l["cluster"] = name
+		}
+		for k, v := range targetLabels[cs.ID] {
+			l[k] = v
+		}
+		labels[cs.ID] = l
+	}
+	return labels
+}
+
+// clusterNames returns the cluster name of each server, querying the
+// servers whose cluster is not known yet, unless the last query failed
+// less than its backoff ago.
+func (ne *NATSExporter) clusterNames(servers []*collector.CollectedServer) map[string]string {
+	ne.Lock()
+	names := make(map[string]string, len(servers))
+	var unknown []*collector.CollectedServer
+	now := time.Now()
+	for _, cs := range servers {
+		if name, ok := ne.clusters[cs.ID]; ok {
+			names[cs.ID] = name
+		} else if r, ok := ne.clusterRetries[cs.ID]; !ok || !now.Before(r.at) {
+			unknown = append(unknown, cs)
+		}
+	}
+	transport := ne.transport
+	ne.Unlock()
+	if transport == nil && len(unknown) > 0 {
+		var err error
+		if transport, err = ne.newTransport(); err != nil {
+			collector.Errorf("Unable to query the cluster of the servers: %v", err)
+			return names
+		}
+		defer transport.Close()
+	}
+
+	for _, cs := range unknown {
+		name, err := transport.QueryClusterNameFromVarz(cs.URL)
+		ne.Lock()
+		if err != nil {
+			backoff := clusterQueryBackoff
+			if r, ok := ne.clusterRetries[cs.ID]; ok {
+				backoff = r.backoff * 2
+				if backoff > clusterQueryMaxBackoff {
+					backoff = clusterQueryMaxBackoff
+				}
+			}
+			if ne.clusterRetries == nil {
+				ne.clusterRetries = make(map[string]clusterRetry)
+			}
+			ne.clusterRetries[cs.ID] = clusterRetry{at: time.Now().Add(backoff), backoff: backoff}
+			ne.Unlock()
+			collector.Debugf("Unable to get the cluster of %s, retrying in %v: %v", cs.ID, backoff, err)
+			continue
+		}
+		names[cs.ID] = name
+		if ne.clusters == nil {
+			ne.clusters = make(map[string]string)
+		}
+		ne.clusters[cs.ID] = name
+		delete(ne.clusterRetries, cs.ID)
+		ne.Unlock()
+	}
+	return names
+}
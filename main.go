@@ -57,6 +57,34 @@ func parseServerIDAndURL(urlArg string) (string, string, error) {
 	return id, monURL, nil
 }
 
+// parsePinnedSHA256 parses the pinned fingerprints, either one for all the
+// servers or comma separated url=sha256 pairs, into a function returning
+// the fingerprint pinned for a URL, if any.
+func parsePinnedSHA256(value string) (func(string) string, error) {
+	if !strings.Contains(value, "=") {
+		if _, err := collector.ParseSHA256Fingerprint(value); err != nil {
+			return nil, err
+		}
+		return func(string) string { return value }, nil
+	}
+	pins := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		idx := strings.LastIndex(pair, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid pin %q, expected url=sha256", pair)
+		}
+		monURL, fingerprint := strings.TrimSpace(pair[:idx]), strings.TrimSpace(pair[idx+1:])
+		if _, err := url.ParseRequestURI(monURL); err != nil {
+			return nil, err
+		}
+		if _, err := collector.ParseSHA256Fingerprint(fingerprint); err != nil {
+			return nil, err
+		}
+		pins[monURL] = fingerprint
+	}
+	return func(monURL string) string { return pins[monURL] }, nil
+}
+
 // updateOptions sets up additional options based on the provided flags.
 func updateOptions(debugAndTrace, useSysLog bool, opts *exporter.NATSExporterOptions) {
 	if debugAndTrace {
@@ -78,7 +106,8 @@ func updateOptions(debugAndTrace, useSysLog bool, opts *exporter.NATSExporterOpt
 
 	metricsSpecified := opts.GetConnz || opts.GetVarz || opts.GetSubz || opts.GetHealthz ||
 		opts.GetRoutez || opts.GetGatewayz || opts.GetLeafz || opts.GetStreamingChannelz ||
-		opts.GetStreamingServerz || opts.GetReplicatorVarz || opts.GetJszFilter == ""
+		opts.GetStreamingServerz || opts.GetReplicatorVarz || opts.GetJszFilter == "" || opts.GetAccountEvents ||
+		opts.GetAccountz
 	if !metricsSpecified {
 		// No logger setup yet, so use fmt
 		fmt.Printf("No metrics specified.  Defaulting to varz.\n")
@@ -91,6 +120,10 @@ func main() {
 	var debugAndTrace bool
 	var retryInterval int
 	var printVersion bool
+	var publicMetrics string
+	var sanitizeLabels bool
+	var edgeDomain string
+	var tlsPinnedSHA256 string
 
 	opts := exporter.GetDefaultExporterOptions()
 
@@ -113,28 +146,122 @@ func main() {
 	flag.BoolVar(&opts.Trace, "V", false, "Enable trace log level.")
 	flag.BoolVar(&debugAndTrace, "DV", false, "Enable debug and trace log levels.")
 	flag.BoolVar(&opts.GetConnz, "connz", false, "Get connection metrics.")
+	flag.BoolVar(&opts.ConnzAccountKinds, "connz_account_kinds", false,
+		"Report the connections of each account by kind: client, leaf or route (used with connz).")
 	flag.BoolVar(&opts.GetConnzDetailed, "connz_detailed", false,
 		"Get detailed connection metrics for each client. Enables flag `connz` implicitly.")
+	flag.IntVar(&opts.ConnzIdleTopN, "connz_idle_top", 0,
+		"Report the idle time of the N connections idle the longest (used with connz).")
+	flag.BoolVar(&opts.ConnzSlowConsumersByAccount, "connz_slow_consumers_by_account", false,
+		"Report the slow consumers of each account from the closed connections (used with connz).")
+	flag.Int64Var(&opts.ConnzStreamThreshold, "connz_stream_threshold", 0,
+		"Decode the connections of connz responses larger than this many bytes one at a time (used with connz).")
+	flag.BoolVar(&opts.ConnzTLSVersions, "connz_tls_versions", false,
+		"Report the connections using each TLS version, going through all the connections (used with connz).")
 	flag.BoolVar(&opts.GetHealthz, "healthz", false, "Get health metrics.")
 	flag.BoolVar(&opts.GetReplicatorVarz, "replicatorVarz", false, "Get replicator general metrics.")
 	flag.BoolVar(&opts.GetGatewayz, "gatewayz", false, "Get gateway metrics.")
 	flag.BoolVar(&opts.GetLeafz, "leafz", false, "Get leaf metrics.")
 	flag.BoolVar(&opts.GetRoutez, "routez", false, "Get route metrics.")
+	flag.BoolVar(&opts.GetAccountz, "accountz", false, "Get account metrics.")
+	flag.BoolVar(&opts.AccountzLimits, "accountz_limits", false,
+		"Get the subscriptions of each account along with their limit (used with accountz).")
+	flag.BoolVar(&opts.GetAccountEvents, "account_events", false,
+		"Get account metrics from system account events (used with nats URLs).")
+	flag.BoolVar(&opts.GetConfiguredServers, "configured_servers", false,
+		"Get the servers polled by the exporter, with their monitoring URL.")
+	flag.BoolVar(&opts.GetFetchRTT, "fetch_rtt", false,
+		"Get the time taken by the requests of the exporter to the monitoring endpoints.")
+	flag.BoolVar(&opts.GetHeap, "heap", false,
+		"Get the heap memory in use by the exporter.")
+	flag.BoolVar(&opts.GetScrapeStreak, "scrape_streak", false,
+		"Get the number of consecutive successful, or failed, requests to the monitoring endpoints.")
 	flag.BoolVar(&opts.GetSubz, "subz", false, "Get subscription metrics.")
 	flag.BoolVar(&opts.GetStreamingChannelz, "channelz", false, "Get streaming channel metrics.")
 	flag.BoolVar(&opts.GetStreamingServerz, "serverz", false, "Get streaming server metrics.")
 	flag.BoolVar(&opts.GetVarz, "varz", false, "Get general metrics.")
-	flag.StringVar(&opts.GetJszFilter, "jsz", "", "Select JetStream metrics to filter (e.g streams, accounts, consumers)")
+	flag.StringVar(&opts.GetJszFilter, "jsz", "", "Select JetStream metrics to filter (e.g streams, accounts, consumers, all). jetstream_stream_max_consumer_lag needs consumers or all.")
+	flag.BoolVar(&opts.JszStreamSubjects, "jsz_stream_subjects", false,
+		"Report the subjects captured by each stream (used with jsz streams).")
+	flag.StringVar(&edgeDomain, "edge_domain", "",
+		"Get general, leaf and JetStream stream metrics of an edge server in this JetStream domain.")
 	flag.StringVar(&opts.CertFile, "tlscert", "", "Server certificate file (Enables HTTPS).")
 	flag.StringVar(&opts.KeyFile, "tlskey", "", "Private key for server certificate (used with HTTPS).")
 	flag.StringVar(&opts.CaFile, "tlscacert", "", "Client certificate CA for verification (used with HTTPS).")
 	flag.StringVar(&opts.HTTPUser, "http_user", "", "Enable basic auth and set user name for HTTP scrapes.")
 	flag.StringVar(&opts.HTTPPassword, "http_pass", "", "Set the password for HTTP scrapes. NATS bcrypt supported.")
 	flag.StringVar(&opts.Prefix, "prefix", "", "Replace the default prefix for all the metrics.")
+	flag.BoolVar(&opts.DualEmit, "dual_emit", false,
+		"Also serve the metrics under their default prefix while migrating to a new one (used with prefix).")
 	flag.BoolVar(&opts.UseInternalServerID, "use_internal_server_id", false, "Enables using ServerID from /varz")
 	flag.BoolVar(&opts.UseServerName, "use_internal_server_name", false, "Enables using ServerName from /varz")
+	flag.BoolVar(&opts.ClusterLabel, "cluster_label", false,
+		"Add the cluster name of each server, from varz, as a cluster label to all its metrics.")
+	flag.BoolVar(&opts.DebugLastResponse, "debug_last_response", false,
+		"Serve the last response of each server endpoint at /debug/lastresponse?server=<id>&endpoint=<name>.")
+	flag.StringVar(&opts.DumpFile, "dump_file", "",
+		"Periodically write the metrics to this file, in the text exposition format.")
+	flag.DurationVar(&opts.DumpInterval, "dump_interval", exporter.DefaultDumpInterval,
+		"Interval at which the metrics are written to the dump file.")
+	flag.IntVar(&opts.EndpointConcurrency, "endpoint_concurrency", 0,
+		"Maximum number of requests to the monitoring endpoints of each server running at once.")
+	flag.DurationVar(&opts.FailureGracePeriod, "failure_grace_period", 0,
+		"Keep reporting the last metrics of a server this long after it fails, flagged as stale.")
+	flag.BoolVar(&opts.CountHTTPRequests, "http_requests", false,
+		"Count the requests served by the exporter, by path and status code.")
+	flag.BoolVar(&opts.DedupByServerID, "dedup_by_server_id", false,
+		"Scrape servers reporting the same server_id in /varz only once.")
+	flag.BoolVar(&opts.InstanceLabel, "instance_label", false,
+		"Add an exporter_instance label, set to the hostname unless instance_name is set, to all the metrics.")
+	flag.StringVar(&opts.InstanceName, "instance_name", "",
+		"Set the exporter_instance label to this value (enables instance_label).")
+	flag.StringVar(&opts.PublicListen, "public_listen", "",
+		"Network host:port serving only the metrics selected with public_metrics.")
+	flag.StringVar(&publicMetrics, "public_metrics", "",
+		"Comma separated patterns of the metric names served on public_listen.")
+	flag.BoolVar(&opts.RecoverPanics, "recover_panics", false,
+		"Keep serving the metrics of the other collectors when a collector panics.")
+	flag.BoolVar(&sanitizeLabels, "sanitize_labels", false,
+		"Replace the characters other than [a-zA-Z0-9_] in label values with an underscore.")
+	flag.StringVar(&opts.ScanCIDR, "scan_cidr", "",
+		"Range of addresses scanned for servers, e.g. 10.0.0.0/24, instead of listing them.")
+	flag.DurationVar(&opts.ScanInterval, "scan_interval", exporter.DefaultScanInterval,
+		"Interval at which the scan range is scanned again.")
+	flag.IntVar(&opts.ScanPort, "scan_port", exporter.DefaultScanPort,
+		"Monitoring port of the servers of the scan range.")
+	flag.IntVar(&opts.ScanRate, "scan_rate", exporter.DefaultScanRate,
+		"Maximum number of addresses of the scan range probed per second.")
+	flag.StringVar(&opts.ServerLabelName, "server_label_name", "",
+		"Name of the label identifying the servers in all the metrics, server_id by default.")
+	flag.BoolVar(&opts.SuppressZeroValues, "suppress_zero_values", false,
+		"Leave out the gauge samples whose value is zero.")
+	flag.StringVar(&tlsPinnedSHA256, "tls_pinned_sha256", "",
+		"SHA-256 fingerprint of the certificate of the servers, or comma separated url=sha256 pairs, trusted instead of verifying it against the CAs.")
+	flag.StringVar(&opts.MonitorCertFile, "monitor_tlscert", "",
+		"Client certificate presented to the servers over HTTPS, loaded again when it changes.")
+	flag.StringVar(&opts.MonitorKeyFile, "monitor_tlskey", "",
+		"Key of the client certificate presented to the servers over HTTPS (used with monitor_tlscert).")
+	flag.StringVar(&opts.SystemCredsFile, "system_creds", "",
+		"Credentials file of the system account user, instead of the user and password of nats URLs.")
+	flag.StringVar(&opts.SystemNKeyFile, "system_nkey", "",
+		"Nkey seed file of the system account user, instead of the user and password of nats URLs.")
+	flag.StringVar(&opts.TargetsFile, "targets_file", "",
+		"Prometheus file_sd JSON file listing the servers to monitor, reloaded on changes.")
 	flag.Parse()
 
+	if opts.InstanceName != "" {
+		opts.InstanceLabel = true
+	}
+	if edgeDomain != "" {
+		exporter.ApplyEdgePreset(opts, edgeDomain)
+	}
+	if sanitizeLabels {
+		opts.LabelSanitizer = exporter.ReplaceInvalidLabelChars
+	}
+	if publicMetrics != "" {
+		opts.PublicMetrics = strings.Split(publicMetrics, ",")
+	}
+
 	opts.RetryInterval = time.Duration(retryInterval) * time.Second
 
 	if printVersion {
@@ -143,7 +270,7 @@ func main() {
 	}
 
 	args := flag.Args()
-	if len(args) < 1 {
+	if len(args) < 1 && opts.TargetsFile == "" && opts.ScanCIDR == "" {
 		fmt.Printf("Usage:  %s <flags> url\n\n", os.Args[0])
 		flag.Usage()
 		return
@@ -158,6 +285,36 @@ necessary.`)
 
 	updateOptions(debugAndTrace, useSysLog, opts)
 
+	pinnedSHA256 := func(string) string { return "" }
+	if tlsPinnedSHA256 != "" {
+		var err error
+		if pinnedSHA256, err = parsePinnedSHA256(tlsPinnedSHA256); err != nil {
+			collector.Fatalf("Unable to parse the pinned certificates %q: %v", tlsPinnedSHA256, err)
+		}
+	}
+	addServer := func(exp *exporter.NATSExporter, id, url string) error {
+		if fingerprint := pinnedSHA256(url); fingerprint != "" {
+			return exp.AddPinnedServer(id, url, fingerprint)
+		}
+		return exp.AddServer(id, url)
+	}
+	// queryVarz queries the server with its certificate pinned, if any.
+	queryVarz := func(url string, query func(*collector.Transport) string) string {
+		transport := collector.NewTransport(0)
+		defer transport.Close()
+		if opts.MonitorCertFile != "" || opts.MonitorKeyFile != "" {
+			if err := transport.SetClientCertificate(opts.MonitorCertFile, opts.MonitorKeyFile); err != nil {
+				collector.Fatalf("Unable to load the monitoring client certificate: %v", err)
+			}
+		}
+		if fingerprint := pinnedSHA256(url); fingerprint != "" {
+			if err := transport.PinServerCertificate(url, fingerprint); err != nil {
+				collector.Fatalf("Unable to pin the certificate of %s: %v", url, err)
+			}
+		}
+		return query(transport)
+	}
+
 	// Create an instance of the NATS exporter.
 	exp := exporter.NewExporter(opts)
 
@@ -165,16 +322,20 @@ necessary.`)
 	case len(args) == 1 && opts.UseInternalServerID:
 		// Pick the server id from the /varz endpoint info.
 		url := flag.Args()[0]
-		id := collector.GetServerIDFromVarz(url, opts.RetryInterval)
-		if err := exp.AddServer(id, url); err != nil {
+		id := queryVarz(url, func(t *collector.Transport) string {
+			return t.GetServerIDFromVarz(url, opts.RetryInterval)
+		})
+		if err := addServer(exp, id, url); err != nil {
 			collector.Fatalf("Unable to setup server in exporter: %s, %s: %v", id, url, err)
 		}
 
 	case len(args) == 1 && opts.UseServerName:
 		// Pick the server name from the /varz endpoint info.
 		url := flag.Args()[0]
-		id := collector.GetServerNameFromVarz(url, opts.RetryInterval)
-		if err := exp.AddServer(id, url); err != nil {
+		id := queryVarz(url, func(t *collector.Transport) string {
+			return t.GetServerNameFromVarz(url, opts.RetryInterval)
+		})
+		if err := addServer(exp, id, url); err != nil {
 			collector.Fatalf("Unable to setup server in exporter: %s, %s: %v", id, url, err)
 		}
 
@@ -187,7 +348,7 @@ necessary.`)
 			if err != nil {
 				collector.Fatalf("Unable to parse URL %q: %v", arg, err)
 			}
-			if err := exp.AddServer(id, url); err != nil {
+			if err := addServer(exp, id, url); err != nil {
 				collector.Fatalf("Unable to setup server in exporter: %s, %s: %v",
 					id, url, err)
 			}
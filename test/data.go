@@ -1,5 +1,10 @@
 package test
 
+import (
+	"fmt"
+	"strings"
+)
+
 // GatewayzTestResponse is static data for tests
 func GatewayzTestResponse() string {
 	return `{
@@ -20,7 +25,7 @@ func GatewayzTestResponse() string {
 				"rtt": "80.711681ms",
 				"uptime": "14h4m10s",
 				"idle": "1s",
-				"pending_bytes": 0,
+				"pending_bytes": 2048,
 				"in_msgs": 0,
 				"out_msgs": 656564,
 				"in_bytes": 0,
@@ -145,3 +150,512 @@ func leafzTestResponse() string {
 	]
 }`
 }
+
+// LeafzAccountsTestResponse is static leafz data for a hub with leaf node
+// connections bound to various accounts.
+func LeafzAccountsTestResponse() string {
+	accounts := []string{"TENANT_A", "TENANT_B", "TENANT_A", "$G", "TENANT_A"}
+	leafs := make([]string, 0, len(accounts))
+	for i, account := range accounts {
+		leafs = append(leafs, fmt.Sprintf(`{
+			"account": %q,
+			"ip": "10.0.1.%d",
+			"port": 7422,
+			"rtt": "1ms",
+			"in_msgs": 0,
+			"out_msgs": 0,
+			"in_bytes": 0,
+			"out_bytes": 0,
+			"subscriptions": 0
+		}`, account, i+1))
+	}
+	return fmt.Sprintf(`{
+	"server_id": "SERVER_ID",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"leafnodes": %d,
+	"leafs": [%s]
+}`, len(leafs), strings.Join(leafs, ","))
+}
+
+// RoutezTestResponse is static routez data for a server with a route to
+// each of the remote servers, with 1KB more pending on each route.
+func RoutezTestResponse(serverID string, remoteIDs ...string) string {
+	routes := make([]string, 0, len(remoteIDs))
+	for i, remote := range remoteIDs {
+		routes = append(routes, fmt.Sprintf(`{
+			"rid": %d,
+			"remote_id": %q,
+			"did_solicit": true,
+			"is_configured": true,
+			"ip": "10.0.0.%d",
+			"port": 6222,
+			"pending_size": %d,
+			"in_msgs": 0,
+			"out_msgs": 0,
+			"subscriptions": 0
+		}`, i+1, remote, i+1, (i+1)*1024))
+	}
+	return fmt.Sprintf(`{
+	"server_id": %q,
+	"now": "2023-06-12T09:48:27.784003Z",
+	"num_routes": %d,
+	"routes": [%s]
+}`, serverID, len(remoteIDs), strings.Join(routes, ","))
+}
+
+// ConnzIdleTestResponse is static connz data with connections idle for
+// various amounts of time.
+func ConnzIdleTestResponse() string {
+	return `{
+	"server_id": "SERVER_ID",
+	"now": "2021-05-07T18:13:47.70796395Z",
+	"num_connections": 4,
+	"total": 4,
+	"offset": 0,
+	"limit": 1024,
+	"connections": [
+		{
+			"cid": 1,
+			"ip": "127.0.0.1",
+			"port": 50001,
+			"idle": "1s",
+			"name": "busy"
+		},
+		{
+			"cid": 2,
+			"ip": "127.0.0.1",
+			"port": 50002,
+			"idle": "1d2h",
+			"name": "leaked"
+		},
+		{
+			"cid": 3,
+			"ip": "127.0.0.1",
+			"port": 50003,
+			"idle": "5m",
+			"name": "quiet"
+		},
+		{
+			"cid": 4,
+			"ip": "127.0.0.1",
+			"port": 50004,
+			"idle": "2h3m",
+			"name": "sleepy"
+		}
+	]
+}`
+}
+
+// ConnzTLSTestResponse is a page of static connz data with connections
+// using various TLS versions, one of them not using TLS.
+func ConnzTLSTestResponse(offset, limit int) string {
+	versions := []string{"1.3", "1.2", "1.3", "", "1.3"}
+	var conns []string
+	for i := offset; i < len(versions) && i < offset+limit; i++ {
+		conns = append(conns, fmt.Sprintf(`{
+			"cid": %d,
+			"ip": "127.0.0.1",
+			"port": %d,
+			"tls_version": %q
+		}`, i+1, 50001+i, versions[i]))
+	}
+	return fmt.Sprintf(`{
+	"server_id": "SERVER_ID",
+	"now": "2021-05-07T18:13:47.70796395Z",
+	"num_connections": %d,
+	"total": %d,
+	"offset": %d,
+	"limit": %d,
+	"connections": [%s]
+}`, len(conns), len(versions), offset, limit, strings.Join(conns, ","))
+}
+
+// ConnzClosedTestResponse is a page of static connz data with closed
+// connections of two accounts, some of them closed as slow consumers.
+func ConnzClosedTestResponse(offset, limit int) string {
+	closed := []struct{ account, reason string }{
+		{"TENANT_A", "Slow Consumer (Write Deadline)"},
+		{"TENANT_B", "Client Closed"},
+		{"TENANT_A", "Slow Consumer (Pending Bytes)"},
+		{"TENANT_B", "Slow Consumer (Write Deadline)"},
+		{"TENANT_A", "Stale Connection"},
+	}
+	var conns []string
+	for i := offset; i < len(closed) && i < offset+limit; i++ {
+		conns = append(conns, fmt.Sprintf(`{
+			"cid": %d,
+			"ip": "127.0.0.1",
+			"port": %d,
+			"account": %q,
+			"reason": %q
+		}`, i+1, 50001+i, closed[i].account, closed[i].reason))
+	}
+	return fmt.Sprintf(`{
+	"server_id": "SERVER_ID",
+	"now": "2021-05-07T18:13:47.70796395Z",
+	"num_connections": %d,
+	"total": %d,
+	"offset": %d,
+	"limit": %d,
+	"connections": [%s]
+}`, len(conns), len(closed), offset, limit, strings.Join(conns, ","))
+}
+
+// ConnzKindsTestResponse is static connz data with connections of several
+// kinds and accounts, paged as if the server limit was limit.
+func ConnzKindsTestResponse(offset, limit int) string {
+	open := []struct{ account, kind string }{
+		{"TENANT_A", "Client"},
+		{"TENANT_A", "Leafnode"},
+		{"TENANT_B", "Client"},
+		{"TENANT_A", "Client"},
+		{"TENANT_B", "Leafnode"},
+		{"TENANT_B", "Router"},
+		{"$SYS", "Client"},
+	}
+	var conns []string
+	for i := offset; i < len(open) && i < offset+limit; i++ {
+		conns = append(conns, fmt.Sprintf(`{
+			"cid": %d,
+			"kind": %q,
+			"ip": "127.0.0.1",
+			"port": %d,
+			"account": %q
+		}`, i+1, open[i].kind, 50001+i, open[i].account))
+	}
+	return fmt.Sprintf(`{
+	"server_id": "SERVER_ID",
+	"now": "2021-05-07T18:13:47.70796395Z",
+	"num_connections": %d,
+	"total": %d,
+	"offset": %d,
+	"limit": %d,
+	"connections": [%s]
+}`, len(conns), len(open), offset, limit, strings.Join(conns, ","))
+}
+
+// ConnzLargeTestResponse is static connz data with n connections.
+func ConnzLargeTestResponse(n int) string {
+	conns := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		conns = append(conns, fmt.Sprintf(`{
+			"cid": %d,
+			"kind": "Client",
+			"type": "nats",
+			"ip": "10.0.%d.%d",
+			"port": %d,
+			"start": "2021-05-07T04:09:37.199417434Z",
+			"last_activity": "2021-05-07T18:13:46.638957061Z",
+			"rtt": "%dµs",
+			"uptime": "14h4m10s",
+			"idle": "%ds",
+			"pending_bytes": %d,
+			"in_msgs": %d,
+			"out_msgs": %d,
+			"in_bytes": %d,
+			"out_bytes": %d,
+			"subscriptions": %d,
+			"name": "client-%d",
+			"lang": "go",
+			"version": "1.27.1",
+			"tls_version": "1.3",
+			"tls_cipher_suite": "TLS_AES_128_GCM_SHA256"
+		}`, i+1, i/256, i%256, 40000+i, 100+i, i%60, i%7, i, 2*i, 100*i, 200*i, i%5, i))
+	}
+	return fmt.Sprintf(`{
+	"server_id": "SERVER_ID",
+	"now": "2021-05-07T18:13:47.70796395Z",
+	"num_connections": %d,
+	"total": %d,
+	"offset": 0,
+	"limit": %d,
+	"connections": [%s]
+}`, n, n, n, strings.Join(conns, ","))
+}
+
+// AccountzTestResponse is static accountz data for a server with three
+// accounts.
+func AccountzTestResponse() string {
+	return `{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"system_account": "$SYS",
+	"accounts": ["$G", "$SYS", "ORDERS"]
+}`
+}
+
+// AccountzDetailTestResponse is static accountz data with the detail of an
+// account, limiting its subscriptions through its JWT unless limit is empty.
+func AccountzDetailTestResponse(account string, subscriptions int, limit string) string {
+	var claim string
+	if limit != "" {
+		claim = fmt.Sprintf(`,
+		"decoded_jwt": {
+			"name": %q,
+			"nats": {
+				"limits": {"subs": %s, "conn": -1, "leaf": -1, "data": -1, "payload": -1}
+			}
+		}`, account, limit)
+	}
+	return fmt.Sprintf(`{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"account_detail": {
+		"account_name": %q,
+		"expired": false,
+		"complete": true,
+		"subscriptions": %d%s
+	}
+}`, account, subscriptions, claim)
+}
+
+// VarzTestResponse is static varz data for the server serving the static
+// JetStream data.
+func VarzTestResponse() string {
+	return `{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"server_name": "hub-1",
+	"version": "2.9.19",
+	"connections": 3,
+	"ping_interval": 30000000000,
+	"ping_max": 3,
+	"max_control_line": 4096,
+	"max_payload": 1048576
+}`
+}
+
+// JszTestResponse is static jsz data with the details of all the streams
+// and consumers.
+func JszTestResponse() string {
+	return `{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"config": {
+		"max_memory": 1073741824,
+		"max_storage": 10737418240,
+		"store_dir": "/data/jetstream",
+		"domain": "hub"
+	},
+	"memory": 0,
+	"storage": 1024,
+	"reserved_memory": 268435456,
+	"reserved_storage": 2147483648,
+	"accounts": 1,
+	"ha_assets": 0,
+	"api": {
+		"total": 42,
+		"errors": 2,
+		"inflight": 3
+	},
+	"streams": 2,
+	"consumers": 2,
+	"messages": 15,
+	"bytes": 1024,
+	"account_details": [
+		{
+			"name": "$G",
+			"id": "$G",
+			"memory": 0,
+			"storage": 1024,
+			"reserved_memory": 0,
+			"reserved_storage": 0,
+			"accounts": 0,
+			"ha_assets": 0,
+			"api": {
+				"total": 0,
+				"errors": 0
+			},
+			"stream_detail": [
+				{
+					"name": "ORDERS",
+					"created": "2023-06-12T09:40:00.000000Z",
+					"config": {
+						"name": "ORDERS",
+						"subjects": ["orders.>", "returns.*"],
+						"retention": "limits",
+						"max_consumers": 10,
+						"storage": "file",
+						"num_replicas": 1
+					},
+					"state": {
+						"messages": 10,
+						"bytes": 800,
+						"first_seq": 3,
+						"first_ts": "2023-06-12T09:41:00Z",
+						"last_seq": 14,
+						"last_ts": "2023-06-12T09:48:00Z",
+						"num_deleted": 2,
+						"consumer_count": 2
+					},
+					"consumer_detail": [
+						{
+							"stream_name": "ORDERS",
+							"name": "billing",
+							"created": "2023-06-12T09:40:10.000000Z",
+							"config": {
+								"name": "billing",
+								"deliver_subject": "deliver.billing",
+								"deliver_policy": "all",
+								"ack_policy": "explicit"
+							},
+							"delivered": {
+								"consumer_seq": 12,
+								"stream_seq": 14
+							},
+							"ack_floor": {
+								"consumer_seq": 10,
+								"stream_seq": 12
+							},
+							"num_ack_pending": 2,
+							"num_redelivered": 0,
+							"num_waiting": 0,
+							"num_pending": 0
+						},
+						{
+							"stream_name": "ORDERS",
+							"name": "shipping",
+							"created": "2023-06-12T09:40:20.000000Z",
+							"config": {
+								"name": "shipping",
+								"deliver_policy": "all",
+								"ack_policy": "explicit",
+								"inactive_threshold": 300000000000
+							},
+							"delivered": {
+								"consumer_seq": 6,
+								"stream_seq": 8,
+								"last_active": "2023-06-12T09:47:27.784003Z"
+							},
+							"ack_floor": {
+								"consumer_seq": 4,
+								"stream_seq": 6
+							},
+							"num_ack_pending": 2,
+							"num_redelivered": 1,
+							"num_waiting": 0,
+							"num_pending": 6
+						}
+					]
+				},
+				{
+					"name": "EVENTS",
+					"created": "2023-06-12T09:40:30.000000Z",
+					"state": {
+						"messages": 5,
+						"bytes": 224,
+						"first_seq": 1,
+						"first_ts": "2023-06-12T09:42:00Z",
+						"last_seq": 8,
+						"last_ts": "2023-06-12T09:47:00Z",
+						"num_deleted": 3,
+						"lost": {
+							"msgs": [6, 7],
+							"bytes": 210
+						},
+						"consumer_count": 0
+					}
+				}
+			]
+		}
+	]
+}`
+}
+
+// JszReplicasTestResponse is static jsz data for an account with single
+// replica and replicated streams, some of them without their config.
+func JszReplicasTestResponse() string {
+	stream := func(name, config, cluster string) string {
+		return fmt.Sprintf(`{
+				"name": %q,
+				"created": "2023-06-12T09:40:00.000000Z",%s%s
+				"state": {
+					"messages": 0,
+					"bytes": 0,
+					"first_seq": 0,
+					"last_seq": 0,
+					"consumer_count": 0
+				}
+			}`, name, config, cluster)
+	}
+	replicas := func(n int) string {
+		return fmt.Sprintf(`
+				"config": {"name": "stream", "num_replicas": %d},`, n)
+	}
+	const cluster = `
+				"cluster": {
+					"name": "hub",
+					"leader": "hub-1",
+					"replicas": [{"name": "hub-2", "current": true}, {"name": "hub-3", "current": true}]
+				},`
+	streams := []string{
+		stream("R1", replicas(1), ""),
+		stream("R3", replicas(3), cluster),
+		stream("CLUSTERED", "", cluster),
+		stream("STANDALONE", "", ""),
+	}
+	return fmt.Sprintf(`{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"config": {"domain": "hub"},
+	"streams": %d,
+	"account_details": [
+		{
+			"name": "TENANT",
+			"id": "TENANT",
+			"stream_detail": [%s]
+		}
+	]
+}`, len(streams), strings.Join(streams, ","))
+}
+
+// JszIdleAPITestResponse is static jsz data of a server without inflight
+// API requests, which leaves them out of the API stats.
+func JszIdleAPITestResponse() string {
+	return `{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"config": {"domain": "hub"},
+	"api": {
+		"total": 42,
+		"errors": 2
+	},
+	"streams": 0
+}`
+}
+
+// JszShrunkClusterTestResponse is static jsz data of a server of a meta
+// cluster of 3 servers, one of them offline.
+func JszShrunkClusterTestResponse() string {
+	return `{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": "2023-06-12T09:48:27.784003Z",
+	"config": {"domain": "hub"},
+	"meta_cluster": {
+		"name": "hub",
+		"leader": "hub-1",
+		"peer": "yrzKKRBu",
+		"replicas": [
+			{"name": "hub-2", "current": true, "active": 120000000, "peer": "cnrtt3eg"},
+			{"name": "hub-3", "current": false, "offline": true, "active": 0, "peer": "b2oh2L6w"}
+		],
+		"cluster_size": 3
+	},
+	"streams": 0
+}`
+}
+
+// JszMetaTestResponse is static jsz data for a server of a JetStream
+// cluster, as of now, with the given meta leader.
+func JszMetaTestResponse(now, leader string) string {
+	return fmt.Sprintf(`{
+	"server_id": "NCUOUT5DNO7VVPWCQ5N2PZKM5NEPCNYVZ6KQ4ZVL5KS7NTLQVF7FXUUE",
+	"now": %q,
+	"config": {"domain": "hub"},
+	"meta_cluster": {
+		"name": "hub",
+		"leader": %q,
+		"cluster_size": 3
+	},
+	"streams": 0
+}`, now, leader)
+}
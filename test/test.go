@@ -19,8 +19,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/http/httptest"
 	"strconv"
 	"sync"
 	"testing"
@@ -88,6 +87,19 @@ func RunLeafzStaticServer(wg *sync.WaitGroup) *http.Server {
 	return srv
 }
 
+// RunStaticServer starts an http server on a random port serving static
+// content, keyed by the URL path. Query parameters are ignored.
+func RunStaticServer(responses map[string]string) *httptest.Server {
+	mux := http.NewServeMux()
+	for path, body := range responses {
+		body := body
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
 // RunStreamingServerWithPorts runs the STAN server in a go routine allowing
 // the clusterID and ports to be specified..
 func RunStreamingServerWithPorts(clusterID string, port, monitorPort int) *nss.StanServer {
@@ -178,22 +190,63 @@ func RunServerWithPorts(cport, mport int) *server.Server {
 	return RunServerWithPortsAndName(cport, mport, "")
 }
 
-var tempRoot = filepath.Join(os.TempDir(), "exporter")
-
-// RunJetStreamServerWithPorts starts a JetStream server.
-func RunJetStreamServerWithPorts(port, monitorPort int, domain string) *server.Server {
+// RunJetStreamServerWithPorts starts a JetStream server storing its data in
+// a temporary directory removed at the end of the test.
+func RunJetStreamServerWithPorts(t *testing.T, port, monitorPort int, domain string) *server.Server {
 	opts := natsserver.DefaultTestOptions
 	opts.Port = port
 	opts.JetStream = true
 	opts.JetStreamDomain = domain
-	tdir, _ := os.MkdirTemp(tempRoot, "js-storedir-")
-	opts.StoreDir = filepath.Dir(tdir)
+	opts.StoreDir = t.TempDir()
 	opts.HTTPHost = "127.0.0.1"
 	opts.HTTPPort = monitorPort
 
 	return natsserver.RunServer(&opts)
 }
 
+// SystemAccountNKeySeed is the seed of the nkey of a user of the system
+// account of the servers started by RunSystemAccountServer.
+const SystemAccountNKeySeed = "SUAM3Z7UWG3GKGVNHPVXAL65CRALMA3RXFNXFUPOVR2VYNTVIMIQENHF74"
+
+const systemAccountNKey = "UCATIUO53HCJ7EQ3UCHM75JTMBFF6PIZ7O6BIEYG4PEMODQKCZ7CRBEC"
+
+// RunSystemAccountServer starts a NATS server with a system account, which
+// the user "sys" with password "sys" belongs to.
+func RunSystemAccountServer(port int) *server.Server {
+	return natsserver.RunServer(systemAccountOptions(port))
+}
+
+// RunSystemAccountServerWithRoutes starts a NATS server like
+// RunSystemAccountServer, in a cluster listening on clusterPort and routed
+// to the given cluster URLs.
+func RunSystemAccountServerWithRoutes(port, clusterPort int, routes ...string) *server.Server {
+	opts := systemAccountOptions(port)
+	opts.Cluster.Name = "sys-cluster"
+	opts.Cluster.Host = "127.0.0.1"
+	opts.Cluster.Port = clusterPort
+	for _, r := range routes {
+		opts.Routes = append(opts.Routes, server.RoutesFromStr(r)...)
+	}
+	return natsserver.RunServer(opts)
+}
+
+func systemAccountOptions(port int) *server.Options {
+	sys := server.NewAccount("SYS")
+	app := server.NewAccount("APP")
+
+	opts := natsserver.DefaultTestOptions
+	opts.Port = port
+	opts.NoSystemAccount = false
+	opts.Accounts = []*server.Account{sys, app}
+	opts.SystemAccount = "SYS"
+	opts.Users = []*server.User{
+		{Username: "sys", Password: "sys", Account: sys},
+		{Username: "app", Password: "app", Account: app},
+	}
+	opts.Nkeys = []*server.NkeyUser{{Nkey: systemAccountNKey, Account: sys}}
+	return &opts
+}
+
 func resetPreviousHTTPConnections() {
 	http.DefaultTransport = &http.Transport{}
 }